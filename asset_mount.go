@@ -0,0 +1,163 @@
+package static
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// AssetMount is a directory of static assets, such as a directory served with http.FileServer, that is copied into the OutputDir rather than built with the handler.
+type AssetMount struct {
+	// The URL path prefix the assets are served at. e.g. /static/
+	Prefix string
+	// The directory containing the assets. Ignored if FS is set.
+	Dir string
+	// The file system containing the assets. e.g. an embed.FS
+	FS fs.FS
+	// Hardlink files from Dir into the OutputDir when they cannot be reflinked, instead of copying them. Files in Dir will be modified if their hardlinks in the OutputDir are written to, and their permissions and modification times are set to the FileMode and ModTime of the Options if they are set.
+	Hardlink bool
+}
+
+// copyAssets copies the files of each AssetMount in the Options into the OutputDir, calling the EventHandler for every file.
+func copyAssets(o Options, eh EventHandler) {
+	for _, m := range o.Assets {
+		fsys := m.FS
+		if fsys == nil {
+			fsys = os.DirFS(m.Dir)
+		}
+
+		err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				message := fmt.Sprintf("Unable to read asset %s for prefix %s", name, m.Prefix)
+				eh(Event{Action: COPY, Path: m.Prefix, Error: buildError{message, err}})
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			urlPath := path.Join("/", m.Prefix, name)
+			outputPath := filepath.Join(o.OutputDir, filepath.FromSlash(urlPath))
+			copied, err := copyAsset(o, m, fsys, name, outputPath)
+			action := COPY
+			if err == nil && !copied {
+				action = SKIP
+			}
+			eh(Event{Action: action, Path: urlPath, OutputPath: outputPath, Error: err})
+			return nil
+		})
+		if err != nil {
+			message := fmt.Sprintf("Unable to read assets for prefix %s", m.Prefix)
+			eh(Event{Action: COPY, Path: m.Prefix, Error: buildError{message, err}})
+		}
+	}
+}
+
+// copyAsset copies the named file from the file system to the output path, unless the output path already has the same contents, and sets its permissions and modification time. Returns true if the file was copied.
+func copyAsset(o Options, m AssetMount, fsys fs.FS, name, outputPath string) (bool, error) {
+	srcInfo, err := fs.Stat(fsys, name)
+	if err != nil {
+		message := fmt.Sprintf("Unable to read asset %s", name)
+		return false, buildError{message, err}
+	}
+
+	var srcPath string
+	if m.FS == nil {
+		srcPath = filepath.Join(m.Dir, filepath.FromSlash(name))
+	}
+
+	if unchanged(o, fsys, name, srcPath, srcInfo, outputPath) {
+		return false, setAssetMetadata(o, outputPath, name, srcInfo)
+	}
+
+	outputDir := filepath.Dir(outputPath)
+	err = mkdirAll(o, outputDir)
+	if err != nil {
+		message := fmt.Sprintf("Unable to create dir %s for asset %s", outputDir, name)
+		return false, buildError{message, err}
+	}
+	os.Remove(outputPath)
+
+	if srcPath != "" {
+		if reflink(srcPath, outputPath) == nil || m.Hardlink && os.Link(srcPath, outputPath) == nil {
+			return true, setAssetMetadata(o, outputPath, name, srcInfo)
+		}
+	}
+
+	src, err := fsys.Open(name)
+	if err != nil {
+		message := fmt.Sprintf("Unable to read asset %s", name)
+		return false, buildError{message, err}
+	}
+	defer src.Close()
+
+	f, err := createFile(o, outputPath, name)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, src)
+	if err != nil {
+		message := fmt.Sprintf("Unable to write file %s for asset %s", outputPath, name)
+		return false, buildError{message, err}
+	}
+	return true, setAssetMetadata(o, outputPath, name, srcInfo)
+}
+
+// setAssetMetadata sets the permissions of the output file to the FileMode of the Options if it is set, and its modification time to the ModTime of the Options if it is set, otherwise to the modification time of the asset if it has one.
+func setAssetMetadata(o Options, outputPath, name string, srcInfo fs.FileInfo) error {
+	if o.FileMode != 0 {
+		err := os.Chmod(outputPath, o.FileMode)
+		if err != nil {
+			message := fmt.Sprintf("Unable to set permissions of file %s for asset %s", outputPath, name)
+			return buildError{message, err}
+		}
+	}
+
+	modTime := o.ModTime
+	if modTime.IsZero() {
+		modTime = srcInfo.ModTime()
+	}
+	if modTime.IsZero() {
+		return nil
+	}
+	err := os.Chtimes(outputPath, modTime, modTime)
+	if err != nil {
+		message := fmt.Sprintf("Unable to set modification time of file %s for asset %s", outputPath, name)
+		return buildError{message, err}
+	}
+	return nil
+}
+
+// unchanged reports if the output path already has the contents of the named file. Files with a modification time are compared by size and modification time, and files without one, such as those in an embed.FS, or whose output file is given the ModTime of the Options, by contents.
+func unchanged(o Options, fsys fs.FS, name, srcPath string, srcInfo fs.FileInfo, outputPath string) bool {
+	outputInfo, err := os.Stat(outputPath)
+	if err != nil || !outputInfo.Mode().IsRegular() || outputInfo.Size() != srcInfo.Size() {
+		return false
+	}
+
+	if srcPath != "" {
+		if fi, err := os.Stat(srcPath); err == nil && os.SameFile(fi, outputInfo) {
+			return true
+		}
+	}
+
+	if !srcInfo.ModTime().IsZero() && o.ModTime.IsZero() {
+		return outputInfo.ModTime().Equal(srcInfo.ModTime())
+	}
+
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return false
+	}
+	output, err := os.ReadFile(outputPath)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(src, output)
+}
@@ -0,0 +1,175 @@
+package static_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"4d63.com/static"
+)
+
+func TestBuildAssets(t *testing.T) {
+	t.Log("When a Handler is defined that serves no paths.")
+	handler := http.NewServeMux()
+
+	t.Log("And there is a directory of assets containing a css file.")
+	tempDir, _ := ioutil.TempDir("", "")
+	assetsDir := filepath.Join(tempDir, "assets")
+	os.MkdirAll(filepath.Join(assetsDir, "css"), 0755)
+	ioutil.WriteFile(filepath.Join(assetsDir, "css", "site.css"), []byte("body{}"), 0644)
+
+	t.Log("And there is a file system of assets containing a js file.")
+	fsys := fstest.MapFS{"js/site.js": &fstest.MapFile{Data: []byte("alert(1)")}}
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and the assets mounted at /static/ and /scripts.")
+	options := static.DefaultOptions
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Assets = []static.AssetMount{
+		{Prefix: "/static/", Dir: assetsDir},
+		{Prefix: "/scripts", FS: fsys},
+	}
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	build := func() []static.Event {
+		var events []static.Event
+		static.Build(options, handler, nil, func(e static.Event) {
+			t.Logf("Event received => %v", e)
+			events = append(events, e)
+		})
+		sort.Slice(events, func(i, j int) bool { return events[i].Path < events[j].Path })
+		return events
+	}
+
+	cssOutputPath := filepath.Join(options.OutputDir, "static", "css", "site.css")
+	jsOutputPath := filepath.Join(options.OutputDir, "scripts", "js", "site.js")
+
+	t.Log("Expect Build to copy each asset into the OutputDir and send a copy event for each.")
+	expected := []static.Event{
+		{Action: static.COPY, Path: "/scripts/js/site.js", OutputPath: jsOutputPath},
+		{Action: static.COPY, Path: "/static/css/site.css", OutputPath: cssOutputPath},
+	}
+	if events := build(); !eventsEqual(events, expected) {
+		t.Errorf("Events => %v, expected %v", events, expected)
+	}
+	for outputPath, expectedContents := range map[string]string{cssOutputPath: "body{}", jsOutputPath: "alert(1)"} {
+		contents, err := ioutil.ReadFile(outputPath)
+		if err != nil || string(contents) != expectedContents {
+			t.Errorf("Contents of %s => %s, %v, expected %s", outputPath, contents, err, expectedContents)
+		}
+	}
+
+	t.Log("Expect building again to skip the unchanged assets.")
+	expected = []static.Event{
+		{Action: static.SKIP, Path: "/scripts/js/site.js", OutputPath: jsOutputPath},
+		{Action: static.SKIP, Path: "/static/css/site.css", OutputPath: cssOutputPath},
+	}
+	if events := build(); !eventsEqual(events, expected) {
+		t.Errorf("Events => %v, expected %v", events, expected)
+	}
+
+	t.Log("Expect building again after the css file changes to copy only the css file.")
+	ioutil.WriteFile(filepath.Join(assetsDir, "css", "site.css"), []byte("body{color:red}"), 0644)
+	later := time.Now().Add(time.Second)
+	os.Chtimes(filepath.Join(assetsDir, "css", "site.css"), later, later)
+	expected = []static.Event{
+		{Action: static.SKIP, Path: "/scripts/js/site.js", OutputPath: jsOutputPath},
+		{Action: static.COPY, Path: "/static/css/site.css", OutputPath: cssOutputPath},
+	}
+	if events := build(); !eventsEqual(events, expected) {
+		t.Errorf("Events => %v, expected %v", events, expected)
+	}
+	contents, err := ioutil.ReadFile(cssOutputPath)
+	if err != nil || string(contents) != "body{color:red}" {
+		t.Errorf("Contents of %s => %s, %v, expected %s", cssOutputPath, contents, err, "body{color:red}")
+	}
+}
+
+func TestBuildAssetsModeAndModTime(t *testing.T) {
+	t.Log("When a Handler is defined that serves no paths.")
+	handler := http.NewServeMux()
+
+	t.Log("And there is a directory of assets containing a css file, and a file system of assets containing a js file.")
+	tempDir, _ := ioutil.TempDir("", "")
+	assetsDir := filepath.Join(tempDir, "assets")
+	os.MkdirAll(assetsDir, 0755)
+	cssPath := filepath.Join(assetsDir, "site.css")
+	ioutil.WriteFile(cssPath, []byte("body{}"), 0644)
+	fsys := fstest.MapFS{"site.js": &fstest.MapFile{Data: []byte("alert(1)")}}
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, a FileMode and ModTime, and the directory mounted with Hardlink.")
+	options := static.DefaultOptions
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.FileMode = 0600
+	options.ModTime = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	options.Assets = []static.AssetMount{
+		{Prefix: "/static/", Dir: assetsDir, Hardlink: true},
+		{Prefix: "/scripts/", FS: fsys},
+	}
+
+	build := func() []static.Event {
+		var events []static.Event
+		static.Build(options, handler, nil, func(e static.Event) {
+			t.Logf("Event received => %v", e)
+			events = append(events, e)
+		})
+		sort.Slice(events, func(i, j int) bool { return events[i].Path < events[j].Path })
+		return events
+	}
+
+	cssOutputPath := filepath.Join(options.OutputDir, "static", "site.css")
+	jsOutputPath := filepath.Join(options.OutputDir, "scripts", "site.js")
+
+	t.Log("Expect Build to give every asset the FileMode and ModTime, however it was copied.")
+	build()
+	for _, outputPath := range []string{cssOutputPath, jsOutputPath} {
+		info, err := os.Stat(outputPath)
+		if err != nil {
+			t.Errorf("Stat(%s) => %v, expected nil", outputPath, err)
+			continue
+		}
+		if info.Mode().Perm() != options.FileMode || !info.ModTime().Equal(options.ModTime) {
+			t.Errorf("Stat(%s) => %v, %v, expected %v, %v", outputPath, info.Mode().Perm(), info.ModTime(), options.FileMode, options.ModTime)
+		}
+	}
+
+	t.Log("Expect building again to skip the unchanged assets.")
+	expected := []static.Event{
+		{Action: static.SKIP, Path: "/scripts/site.js", OutputPath: jsOutputPath},
+		{Action: static.SKIP, Path: "/static/site.css", OutputPath: cssOutputPath},
+	}
+	if events := build(); !eventsEqual(events, expected) {
+		t.Errorf("Events => %v, expected %v", events, expected)
+	}
+
+	t.Log("Expect building again after the css file is replaced with different contents of the same size to copy it, even though the modification time of the output file is the ModTime.")
+	os.Remove(cssPath)
+	ioutil.WriteFile(cssPath, []byte("a{b:c}"), 0644)
+	expected = []static.Event{
+		{Action: static.SKIP, Path: "/scripts/site.js", OutputPath: jsOutputPath},
+		{Action: static.COPY, Path: "/static/site.css", OutputPath: cssOutputPath},
+	}
+	if events := build(); !eventsEqual(events, expected) {
+		t.Errorf("Events => %v, expected %v", events, expected)
+	}
+	contents, err := ioutil.ReadFile(cssOutputPath)
+	if err != nil || string(contents) != "a{b:c}" {
+		t.Errorf("Contents of %s => %s, %v, expected %s", cssOutputPath, contents, err, "a{b:c}")
+	}
+}
+
+func eventsEqual(events, expected []static.Event) bool {
+	if len(events) != len(expected) {
+		return false
+	}
+	for i := range events {
+		if events[i] != expected[i] {
+			return false
+		}
+	}
+	return true
+}
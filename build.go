@@ -1,82 +1,523 @@
 package static
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"iter"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 )
 
 // Build the paths. Uses the http.Handler to get the response for each path, and writes that response to a file with it's respective path in the OutputDir specified in the Options. Does so concurrently as defined in the Options, and calls the EventHandler for every path with an Event that states that the path was built and if an error occurred. EventHandler may be nil.
-func Build(o Options, h http.Handler, paths []string, eh EventHandler) {
+//
+// Paths are canonicalized, resolving . and .. segments and stripping fragments, and a path that is the same as a path already built is skipped and reported to the EventHandler as a duplicate.
+//
+// If the Options define Assets, the files of each AssetMount are copied into the OutputDir before any path is built, and the EventHandler is called for every file.
+//
+// If the Options define Locales, each path is built once for each locale.
+//
+// If the Options define Variants, each path is built once for each of its variants.
+//
+// If the Options are Conditional, paths whose output file already exists are requested with If-Modified-Since and If-None-Match, and the output file is kept as is when the handler responds 304 Not Modified.
+//
+// If the response for a path has no body, such as a 204 No Content response or a response to a HEAD request, the output file is written, skipped, written with a placeholder or the path fails, as defined by the EmptyBody of the Options.
+//
+// The output files of all paths known before the build starts are computed first, and a path whose output file collides with that of another path, because one needs it as a directory or they differ only in case, fails or is resolved as defined by the Collision of the Options.
+//
+// If the Options define PostProcessors, the body of each response is transformed by the PostProcessors matching its Content-Type before it is written, and the EventHandler is called for every PostProcessor applied with the bytes it saved.
+//
+// If the Options define a Manifest, a JSON manifest of every file in the OutputDir with its SHA-256 is written after building, for checking the OutputDir with Verify.
+//
+// If the Options define a RateLimit, paths are started no faster than that rate. If the Options define AdaptiveConcurrency, the number of paths built concurrently is adjusted as paths are built and each new level is reported to the EventHandler.
+//
+// If the Options define MaxErrors or MaxErrorPercent and that many paths fail to build, Build stops building the remaining paths, waits for the paths already being built to finish, and returns an AbortError. Otherwise returns nil.
+func Build(o Options, h http.Handler, paths []string, eh EventHandler) error {
+	return BuildGroups(o, h, []PathGroup{{Paths: paths}}, eh)
+}
+
+// BuildSeq builds the paths yielded by the sequence in the same way as Build. Paths are built while the sequence is still yielding them, and only a few paths are held in memory at a time, so that very large sites can be built without listing all their paths first. Because paths are not held once built, a path yielded twice is built twice and output files of yielded paths are not checked for collisions with each other, unless the Options TrackSourcePaths, which holds every path in memory. If Build stops early, the AbortError only includes the paths the sequence had yielded.
+func BuildSeq(o Options, h http.Handler, paths iter.Seq[string], eh EventHandler) error {
+	return BuildGroups(o, h, []PathGroup{{Source: paths}}, eh)
+}
+
+// BuildSites builds the paths of each site in the same way as Build, using one set of workers for all sites. Requests for a site's paths are made to its Host, and written to its own directory in the OutputDir.
+func BuildSites(o Options, h http.Handler, sites []Site, eh EventHandler) error {
+	groups := make([]PathGroup, len(sites))
+	for i := range sites {
+		groups[i] = PathGroup{Name: sites[i].Host, Paths: sites[i].Paths, Source: sites[i].Source, site: &sites[i]}
+	}
+	return BuildGroups(o, h, groups, eh)
+}
+
+// BuildGroups builds the paths of the groups in the same way as Build, using one set of workers for all groups. Paths in groups with a higher Priority are built first, no more than a group's Concurrency paths of that group are built at once, and a group's paths are not built until the groups it DependsOn have finished building. Returns an error without building any paths if the groups have duplicate names or dependencies that are unknown or cyclic, or if the Locales define a Sitemap without an Origin.
+func BuildGroups(o Options, h http.Handler, groups []PathGroup, eh EventHandler) error {
 	if eh == nil {
 		eh = defaultEventHandler
 	}
 
+	if o.Locales != nil && o.Locales.Sitemap != "" && o.Locales.Origin == "" {
+		message := fmt.Sprintf("Unable to write sitemap %s, Locales has no Origin", o.Locales.Sitemap)
+		return buildError{message, nil}
+	}
+
+	concurrency := newConcurrencyController(o, eh)
+	workers := concurrency.workers(o)
+
+	s, err := newScheduler(groups, workers)
+	if err != nil {
+		return err
+	}
+	s.trackStreamed = o.TrackSourcePaths
+
+	b := builder{
+		o:           o,
+		h:           h,
+		eh:          eh,
+		scheduler:   s,
+		threshold:   newErrorThreshold(o, s.total()),
+		rateLimiter: newRateLimiter(o),
+		concurrency: concurrency,
+	}
+	if o.Locales != nil && o.Locales.Sitemap != "" {
+		b.sitemap = &localeSitemap{paths: map[string]map[string]bool{}}
+	}
+	if o.VariantManifest != "" {
+		b.manifest = &variantManifest{}
+	}
+	b.etags = loadETags(o, eh)
+	b.collisions = b.detectCollisions()
+	b.writes = newFileLocks()
+	if o.Manifest != "" {
+		b.buildManifest = newBuildManifest(o)
+	}
+
+	// Stop the scheduler as soon as the threshold is reached, so that the feed loop is not left waiting on a Source to yield a path.
+	go func() {
+		select {
+		case <-b.threshold.reached:
+			s.stop()
+		case <-s.stopping:
+		}
+	}()
+
 	var wg sync.WaitGroup
 
-	pathsChan := make(chan string)
+	pathsChan := make(chan scheduledPath)
+
+	copyAssets(o, eh)
 
-	for i := 0; i < o.Concurrency; i++ {
+	b.concurrency.start()
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			buildWorker(o, h, pathsChan, eh)
+			buildWorker(&b, pathsChan)
 		}()
 	}
 
-	for _, path := range paths {
-		pathsChan <- path
+feed:
+	for {
+		p, ok := s.next()
+		b.reportDuplicates()
+		if !ok {
+			break
+		}
+		if b.threshold.isReached() {
+			s.unschedule(p)
+			break
+		}
+		select {
+		case pathsChan <- p:
+		case <-b.threshold.reached:
+			s.unschedule(p)
+			break feed
+		}
 	}
 
 	close(pathsChan)
+	s.stop()
+	b.reportDuplicates()
 
 	wg.Wait()
+
+	b.sitemap.write(o, eh)
+	b.manifest.write(o, eh)
+	b.etags.write(o, eh)
+	b.buildManifest.write(o, eh)
+	setDirModTimes(o)
+
+	return b.threshold.abortError(s.unbuilt())
+}
+
+// builder holds the state shared by the workers of a Build.
+type builder struct {
+	o             Options
+	h             http.Handler
+	eh            EventHandler
+	scheduler     *scheduler
+	threshold     *errorThreshold
+	rateLimiter   *rateLimiter
+	concurrency   *concurrencyController
+	sitemap       *localeSitemap
+	manifest      *variantManifest
+	etags         *etagStore
+	collisions    *collisionDetector
+	writes        *fileLocks
+	buildManifest *buildManifest
 }
 
-func buildWorker(o Options, h http.Handler, paths <-chan string, eh EventHandler) {
-	for path := range paths {
-		statusCode, outputPath, err := BuildSingle(o, h, path)
-		eh(Event{Action: "build", StatusCode: statusCode, Path: path, OutputPath: outputPath, Error: err})
+// buildTarget is one build of a path, with the Options and request path it is built with.
+type buildTarget struct {
+	o       Options
+	path    string
+	host    string
+	locale  string
+	variant Variant
+	// Whether the target is one of the path's Variants.
+	varied bool
+	// The position of the variant in the path's Variants, for ordering the variant manifest.
+	variantIndex int
+	// The ETags of output files, for Conditional builds.
+	etags *etagStore
+	// The output files claimed by the targets of the build.
+	collisions *collisionDetector
+	// Whether the path is from a Source and its output file is checked for collisions without being claimed, because the Options do not TrackSourcePaths.
+	unclaimed bool
+	// The locks serializing writes to the same output file.
+	writes *fileLocks
+}
+
+// detectCollisions returns a collisionDetector that has claimed the output files of every path known before the build starts, in the order they are listed, so that which of two colliding paths fails does not depend on the order they are built in. Paths from a Source are claimed as they are built.
+func (b *builder) detectCollisions() *collisionDetector {
+	type claim struct{ path, outputPath string }
+	var claims []claim
+	for _, g := range b.scheduler.groups {
+		for _, path := range g.Paths {
+			for _, t := range b.targets(scheduledPath{path: path, group: g}) {
+				outputPath, err := OutputFilePath(t.o, t.path)
+				if err == nil {
+					claims = append(claims, claim{t.path, outputPath + t.variant.Suffix})
+				}
+			}
+		}
+	}
+
+	outputPaths := make([]string, len(claims))
+	for i, c := range claims {
+		outputPaths[i] = c.outputPath
+	}
+	c := newCollisionDetector(b.o, outputPaths)
+	for _, cl := range claims {
+		c.claim(cl.path, cl.outputPath, true)
+	}
+	return c
+}
+
+// targets returns the builds of the path, for the site of its group if it has one, once for each locale if the Options have Locales, and once for each of the path's Variants.
+func (b *builder) targets(p scheduledPath) []buildTarget {
+	t := buildTarget{o: b.o, path: p.path, etags: b.etags, collisions: b.collisions, writes: b.writes, unclaimed: p.streamed && !b.o.TrackSourcePaths}
+	if site := p.group.site; site != nil {
+		t = site.target(t)
+	}
+
+	targets := []buildTarget{t}
+	if l := b.o.Locales; l != nil {
+		targets = make([]buildTarget, len(l.Locales))
+		for i, locale := range l.Locales {
+			targets[i] = l.target(t, locale)
+		}
+	}
+
+	if b.o.Variants != nil {
+		variants := b.o.Variants(p.path)
+		if len(variants) > 0 {
+			expanded := make([]buildTarget, 0, len(targets)*len(variants))
+			for _, t := range targets {
+				for i, v := range variants {
+					expanded = append(expanded, v.target(t, i))
+				}
+			}
+			targets = expanded
+		}
 	}
+	return targets
 }
 
-// BuildSingle builds a single path. It uses the http.Handler to get the response for each path, and writes that response to a file with it's respective path in the OutputDir specified in the Options. Returns the HTTP status code returned by the handler, the output path written to and an error if one occurs.
+func buildWorker(b *builder, paths <-chan scheduledPath) {
+	for {
+		b.concurrency.acquire()
+		p, ok := <-paths
+		if !ok {
+			b.concurrency.release()
+			return
+		}
+
+		// A path fails if it fails in any of its locales or variants, and counts once towards the error threshold, so that the threshold is of paths and not of builds.
+		// The RateLimit is of paths, so a path is started once however many locales and variants it is built in.
+		b.rateLimiter.wait()
+		var pathErr error
+		for _, t := range b.targets(p) {
+			start := time.Now()
+			r, err := buildSingle(t, b.h, b.eh)
+			b.eh(Event{Action: "build", StatusCode: r.statusCode, Path: t.path, OutputPath: r.outputPath, Error: err, Host: t.host, Locale: t.locale, Variant: t.variant.Accept, Warning: r.warning(), BytesSaved: r.saved})
+			if pathErr == nil {
+				pathErr = err
+			}
+			b.sitemap.record(p.path, t.locale, r.statusCode, err)
+			b.manifest.record(t, r.outputPath, r.statusCode, err)
+			b.buildManifest.record(t, r, err)
+			b.concurrency.record(time.Since(start), r.statusCode, err)
+		}
+		b.threshold.record(p.path, pathErr)
+		b.concurrency.release()
+		b.scheduler.done(p)
+	}
+}
+
+// BuildSingle builds a single path. It uses the http.Handler to get the response for each path, and writes that response to a file with it's respective path in the OutputDir specified in the Options. The modification time of the file is set to the Last-Modified time of the response, if it has one. Returns the HTTP status code returned by the handler, the output path written to and an error if one occurs.
 func BuildSingle(o Options, h http.Handler, path string) (statusCode int, outputPath string, err error) {
-	pathIsDir := strings.HasSuffix(path, "/")
+	etags := loadETags(o, defaultEventHandler)
+	r, err := buildSingle(buildTarget{o: o, path: path, etags: etags}, h, defaultEventHandler)
+	etags.write(o, defaultEventHandler)
+	return r.statusCode, r.outputPath, err
+}
+
+// buildResult is the result of building a single target.
+type buildResult struct {
+	statusCode  int
+	outputPath  string
+	contentType string
+	// The ways the handler used the http.ResponseWriter that net/http would ignore.
+	warnings []string
+	// The bytes removed from the body by post-processing.
+	saved int64
+}
+
+// warning returns the warnings of the result as a single string, or an empty string if there are none.
+func (r buildResult) warning() string {
+	return strings.Join(r.warnings, "; ")
+}
+
+// outputPath returns the output file of the target, claimed from the collision detector of the build, and any warning from resolving a collision. The output file is empty if the target is to be skipped.
+func (t buildTarget) outputPath() (string, []string, error) {
+	outputPath, err := OutputFilePath(t.o, t.path)
+	if err != nil {
+		return "", nil, err
+	}
+	outputPath, warning, err := t.collisions.claim(t.path, outputPath+t.variant.Suffix, !t.unclaimed)
+	if warning != "" {
+		return outputPath, []string{warning}, err
+	}
+	return outputPath, nil, err
+}
 
-	filePath := filepath.FromSlash(path)
-	if pathIsDir {
-		filePath = filepath.Join(filePath, o.DirFilename)
+// buildSingle builds a single target, retrying it if the Options have a RetryPolicy, and calling the EventHandler for every attempt that is retried.
+func buildSingle(t buildTarget, h http.Handler, eh EventHandler) (buildResult, error) {
+	if t.o.Retry != nil || t.o.Conditional != nil || len(t.o.PostProcessors) > 0 {
+		return buildSingleBuffered(t, h, eh)
 	}
 
-	outputPath = filepath.Join(o.OutputDir, filePath)
-	outputDir := filepath.Dir(outputPath)
-	_, err = os.Stat(outputDir)
-	if os.IsNotExist(err) {
-		err = os.MkdirAll(outputDir, 0755)
+	o, path := t.o, t.path
+	outputPath, warnings, err := t.outputPath()
+	if err != nil || outputPath == "" {
+		return buildResult{warnings: warnings}, err
+	}
+	defer t.writes.lock(outputPath)()
+
+	f, err := createFile(o, outputPath, path)
+	if err != nil {
+		return buildResult{}, err
+	}
+
+	rw, err := serve(o, h, path, nil, f)
+	f.Close()
+	if err != nil {
+		return buildResult{}, err
+	}
+
+	r := buildResult{rw.StatusCode(), outputPath, rw.ResponseHeader().Get("Content-Type"), append(warnings, rw.Warnings()...), 0}
+	if rw.BodyLen() == 0 {
+		r.outputPath, err = emptyBody(o, outputPath, path, r.statusCode)
+		if err != nil || r.outputPath == "" {
+			return r, err
+		}
 	}
+
+	err = setModTime(o, outputPath, path, rw.ResponseHeader())
+	if err != nil {
+		return buildResult{}, err
+	}
+	return r, nil
+}
+
+// buildSingleBuffered builds a single path, serving it into memory until an attempt succeeds or the Options RetryPolicy is exhausted, so that only a successful attempt is written to the output path. If the last attempt is still retryable the path fails, and any existing output file is kept as is. If the Options are Conditional and the handler responds 304 Not Modified, the existing output file is kept as is.
+func buildSingleBuffered(t buildTarget, h http.Handler, eh EventHandler) (buildResult, error) {
+	o, path := t.o, t.path
+	p := o.Retry
+	outputPath, warnings, err := t.outputPath()
+	if err != nil || outputPath == "" {
+		return buildResult{warnings: warnings}, err
+	}
+	defer t.writes.lock(outputPath)()
+	maxAttempts := p.maxAttempts()
+	conditions := t.conditions(outputPath)
+
+	body := bytes.Buffer{}
+	for attempt := 1; ; attempt++ {
+		body.Reset()
+		statusCode := 0
+		rw, err := serve(o, h, path, conditions, &body)
+		if err == nil {
+			statusCode = rw.StatusCode()
+		}
+		final := attempt >= maxAttempts
+		if err == nil && final && p.retryable(statusCode, nil) {
+			message := fmt.Sprintf("Unable to build path %s, all %d attempts failed, the last with status %d", path, maxAttempts, statusCode)
+			return buildResult{statusCode: statusCode, warnings: warnings}, buildError{message, nil}
+		}
+		if err == nil && (final || !p.retryable(statusCode, nil)) {
+			r := buildResult{statusCode, outputPath, rw.ResponseHeader().Get("Content-Type"), append(warnings, rw.Warnings()...), 0}
+			if statusCode == http.StatusNotModified && conditions != nil {
+				return r, nil
+			}
+			var data []byte
+			var results []postProcessResult
+			data, results, err = postProcess(o, path, r.contentType, body.Bytes())
+			if err != nil {
+				return buildResult{}, err
+			}
+			for _, result := range results {
+				r.saved += result.saved
+				eh(Event{Action: POSTPROCESS, Path: path, OutputPath: outputPath, Host: t.host, Locale: t.locale, Variant: t.variant.Accept, PostProcessor: result.name, BytesSaved: result.saved})
+			}
+			if len(data) == 0 {
+				r.outputPath, err = emptyBody(o, outputPath, path, statusCode)
+				if err != nil || r.outputPath == "" {
+					return r, err
+				}
+			}
+			header := rw.ResponseHeader()
+			if len(data) > 0 || o.EmptyBody == EmptyBodyWrite {
+				err = writeFile(o, outputPath, path, data)
+			}
+			if err == nil {
+				err = setModTime(o, outputPath, path, header)
+			}
+			if err == nil {
+				t.etags.record(outputPath, header)
+				return r, nil
+			}
+		}
+
+		if final || !p.retryable(statusCode, err) {
+			return buildResult{}, err
+		}
+
+		delay := p.delay(attempt)
+		message := fmt.Sprintf("Attempt %d of %d for path %s failed with status %d, retrying in %s", attempt, maxAttempts, path, statusCode, delay)
+		eh(Event{Action: RETRY, Path: path, StatusCode: statusCode, OutputPath: outputPath, Error: buildError{message, err}, Host: t.host, Locale: t.locale, Variant: t.variant.Accept})
+		time.Sleep(delay)
+	}
+}
+
+// createFile creates the file at the output path with the FileMode of the Options, and any directories above it that do not exist with the DirMode of the Options.
+func createFile(o Options, outputPath, path string) (*os.File, error) {
+	outputDir := filepath.Dir(outputPath)
+	err := mkdirAll(o, outputDir)
 	if err != nil {
 		message := fmt.Sprintf("Unable to create dir %s for path %s", outputDir, path)
-		return 0, "", buildError{message, err}
+		return nil, buildError{message, err}
 	}
 
-	f, err := os.Create(outputPath)
+	mode := o.FileMode
+	if mode == 0 {
+		mode = 0666
+	}
+	f, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err == nil && o.FileMode != 0 {
+		err = f.Chmod(o.FileMode)
+	}
 	if err != nil {
+		if f != nil {
+			f.Close()
+		}
 		message := fmt.Sprintf("Unable to create file %s for path %s", outputPath, path)
-		return 0, "", buildError{message, err}
+		return nil, buildError{message, err}
+	}
+	return f, nil
+}
+
+// mkdirAll creates the directory and any directories above it that do not exist, with the DirMode of the Options regardless of the umask if it is set.
+func mkdirAll(o Options, dir string) error {
+	_, err := os.Stat(dir)
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		_, err := os.Stat(d)
+		if !os.IsNotExist(err) || d == filepath.Dir(d) {
+			break
+		}
+		missing = append(missing, d)
+	}
+
+	mode := o.DirMode
+	if mode == 0 {
+		mode = 0755
+	}
+	err = os.MkdirAll(dir, mode)
+	if err != nil || o.DirMode == 0 {
+		return err
+	}
+	for _, d := range missing {
+		err = os.Chmod(d, o.DirMode)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile creates the file at the output path and writes the body to it.
+func writeFile(o Options, outputPath, path string, body []byte) error {
+	f, err := createFile(o, outputPath, path)
+	if err != nil {
+		return err
 	}
 	defer f.Close()
 
-	r, err := http.NewRequest("GET", path, nil)
+	_, err = f.Write(body)
 	if err != nil {
-		message := fmt.Sprintf("Unable to create http.Request for path %s", path)
-		return 0, "", buildError{message, err}
+		message := fmt.Sprintf("Unable to write file %s for path %s", outputPath, path)
+		return buildError{message, err}
 	}
-	rw := newResponseWriter(f)
+	return nil
+}
+
+// serve calls the http.Handler with a request for the path, adding the conditional headers to the request, and writing the response body to the io.Writer. Returns the responseWriter the handler wrote the response to.
+func serve(o Options, h http.Handler, path string, conditions http.Header, w io.Writer) (*responseWriter, error) {
+	r, err := newRequest(o, path)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range conditions {
+		r.Header[k] = v
+	}
+	rw := newResponseWriter(w)
+	rw.head = r.Method == http.MethodHead
 	h.ServeHTTP(&rw, r)
+	err = rw.finish()
+	if err != nil {
+		message := fmt.Sprintf("Unable to write response for path %s", path)
+		return nil, buildError{message, err}
+	}
 
-	return rw.StatusCode(), outputPath, nil
+	return &rw, nil
 }
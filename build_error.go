@@ -14,3 +14,8 @@ func (e buildError) Error() string {
 	}
 	return s
 }
+
+// Unwrap returns the cause.
+func (e buildError) Unwrap() error {
+	return e.cause
+}
@@ -1,13 +1,16 @@
 package static_test
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"4d63.com/static"
 )
@@ -221,27 +224,27 @@ func TestBuild(t *testing.T) {
 		{
 			filepath.Join(options.OutputDir, "hello", "index.html"),
 			"Hello directory!",
-			static.Event{"build", "/hello/", 200, filepath.Join(options.OutputDir, "hello", "index.html"), nil},
+			static.Event{Action: "build", Path: "/hello/", StatusCode: 200, OutputPath: filepath.Join(options.OutputDir, "hello", "index.html")},
 		},
 		{
 			filepath.Join(options.OutputDir, "hello", "go"),
 			"Hello go!",
-			static.Event{"build", "/hello/go", 200, filepath.Join(options.OutputDir, "hello", "go"), nil},
+			static.Event{Action: "build", Path: "/hello/go", StatusCode: 200, OutputPath: filepath.Join(options.OutputDir, "hello", "go")},
 		},
 		{
 			filepath.Join(options.OutputDir, "hello", "world"),
 			"Hello world!",
-			static.Event{"build", "/hello/world", 200, filepath.Join(options.OutputDir, "hello", "world"), nil},
+			static.Event{Action: "build", Path: "/hello/world", StatusCode: 200, OutputPath: filepath.Join(options.OutputDir, "hello", "world")},
 		},
 		{
 			filepath.Join(options.OutputDir, "hello", "universe"),
 			"Hello universe!",
-			static.Event{"build", "/hello/universe", 200, filepath.Join(options.OutputDir, "hello", "universe"), nil},
+			static.Event{Action: "build", Path: "/hello/universe", StatusCode: 200, OutputPath: filepath.Join(options.OutputDir, "hello", "universe")},
 		},
 		{
 			filepath.Join(options.OutputDir, "bye"),
 			"404 page not found\n",
-			static.Event{"build", "/bye", 404, filepath.Join(options.OutputDir, "bye"), nil},
+			static.Event{Action: "build", Path: "/bye", StatusCode: 404, OutputPath: filepath.Join(options.OutputDir, "bye")},
 		},
 	}
 
@@ -418,3 +421,173 @@ func TestBuildWithNilEventHandler(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildSeq(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /* and response with Hello <path>!")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/hello/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+	})
+
+	t.Log("And Options are defined with defaults and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And the paths to build come from a sequence that waits for each path to be built before yielding the next.")
+	paths := []string{"/hello/go", "/hello/world", "/hello/universe"}
+	built := make(chan string)
+	seq := func(yield func(string) bool) {
+		for _, path := range paths {
+			if !yield(path) {
+				return
+			}
+			t.Logf("Waiting for %s to be built", path)
+			if b := <-built; b != path {
+				t.Errorf("Path built => %s, expected %s", b, path)
+			}
+		}
+	}
+
+	t.Log("Expect BuildSeq to build each path as it is yielded, and write a file for each path with contents Hello <file>!")
+	err := static.BuildSeq(options, handler, seq, func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		built <- e.Path
+	})
+	if err != nil {
+		t.Errorf("BuildSeq() => %v, expected nil", err)
+	}
+
+	for _, path := range paths {
+		outputFilePath := filepath.Join(options.OutputDir, filepath.FromSlash(path))
+		expectedOutputFileContents := fmt.Sprintf("Hello %s!", filepath.Base(path))
+		outputFileContents, err := ioutil.ReadFile(outputFilePath)
+		if err != nil {
+			t.Fatalf("Error opening output file => %#v, expected to exist.", err)
+		}
+		if string(outputFileContents) != expectedOutputFileContents {
+			t.Errorf(`Contents of %s => %s, expected %s`, outputFilePath, outputFileContents, expectedOutputFileContents)
+		}
+	}
+}
+
+func TestBuildSeqFromChanMaxErrors(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /* and response with Hello <path>!")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/hello/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+	})
+
+	t.Log("And Options are defined with defaults, a Concurrency of 1, a MaxErrors of 1 and an OutputDir.")
+	options := static.DefaultOptions
+	options.Concurrency = 1
+	options.MaxErrors = 1
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And a file exists at the same path as the OutputDir (a problem).")
+	f, _ := os.Create(options.OutputDir)
+	defer f.Close()
+
+	t.Log("And the paths to build are sent on a channel.")
+	paths := make(chan string, 3)
+	paths <- "/hello/go"
+	paths <- "/hello/world"
+	paths <- "/hello/universe"
+	close(paths)
+
+	t.Log("Expect BuildSeq to stop after the first error and return an AbortError listing the paths listed but never built. Paths the channel yields after the stop are not waited for, and so may not be listed.")
+	built := 0
+	err := static.BuildSeq(options, handler, static.PathsFromChan(paths), func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		built++
+	})
+	t.Logf("BuildSeq() => %v", err)
+
+	var abortErr static.AbortError
+	if !errors.As(err, &abortErr) {
+		t.Fatalf("BuildSeq() => %#v, expected an AbortError", err)
+	}
+	if built != 1 || len(abortErr.Unbuilt) == 0 || len(abortErr.Unbuilt) > 2 || abortErr.Unbuilt[0] != "/hello/world" {
+		t.Errorf("Paths built => %d, Unbuilt => %v, expected 1 path built and /hello/world first unbuilt", built, abortErr.Unbuilt)
+	}
+}
+
+func TestBuildSeqMaxErrorsBlockedSource(t *testing.T) {
+	t.Log("When a Handler is defined to respond with Hello!")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello!")
+	})
+
+	t.Log("And Options are defined with defaults, a MaxErrors of 1, and a PrepareRequest that fails /a.")
+	options := static.DefaultOptions
+	options.MaxErrors = 1
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.PrepareRequest = func(r *http.Request) error {
+		if r.URL.Path == "/a" {
+			return errors.New("failed")
+		}
+		return nil
+	}
+
+	t.Log("And a sequence that yields /a and then blocks until it is released.")
+	release := make(chan struct{})
+	defer close(release)
+	paths := func(yield func(string) bool) {
+		if !yield("/a") {
+			return
+		}
+		<-release
+		yield("/b")
+	}
+
+	t.Log("Expect BuildSeq to return an AbortError without waiting for the sequence.")
+	done := make(chan error, 1)
+	go func() {
+		done <- static.BuildSeq(options, handler, paths, nil)
+	}()
+	select {
+	case err := <-done:
+		t.Logf("BuildSeq() => %v", err)
+		var abortErr static.AbortError
+		if !errors.As(err, &abortErr) || abortErr.Path != "/a" {
+			t.Errorf("BuildSeq() => %#v, expected an AbortError for /a", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("BuildSeq() did not return while the sequence was blocked")
+	}
+}
+
+func TestBuildWarnings(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /empty with nothing, and to /late by writing the body and then calling WriteHeader with 500.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/late" {
+			fmt.Fprint(w, "Hello!")
+			w.WriteHeader(500)
+		}
+	})
+
+	t.Log("And Options are defined with defaults and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+
+	t.Log("Expect Build to report 200 OK for both paths, and a warning for the superfluous WriteHeader call.")
+	var mutex sync.Mutex
+	events := map[string]static.Event{}
+	static.Build(options, handler, []string{"/empty", "/late"}, func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		mutex.Lock()
+		defer mutex.Unlock()
+		events[e.Path] = e
+	})
+	if e := events["/empty"]; e.StatusCode != 200 || e.Warning != "" {
+		t.Errorf("Event for /empty => %v, expected StatusCode 200 and no Warning", e)
+	}
+	if e := events["/late"]; e.StatusCode != 200 || e.Warning != "superfluous WriteHeader call with status 500" {
+		t.Errorf("Event for /late => %v, expected StatusCode 200 and a Warning", e)
+	}
+}
@@ -0,0 +1,130 @@
+// Command static builds a website to static files from any Go web app that uses `net/http`, or from any web server.
+//
+// The handler is loaded from a Go plugin built from the app, or requests are made to a running server:
+//
+//	static -plugin app.so -paths paths.txt
+//	static -url http://localhost:8080 -paths paths.txt
+//
+// A plugin is a main package built with `go build -buildmode=plugin` that exports a Handler, either as a variable of type http.Handler or a function returning one. It may also export Paths, either as a variable of type []string or a function returning one, which are built when no paths file or sitemap is given.
+//
+// Paths files contain one path per line. Blank lines and lines starting with # are ignored.
+//
+// If the SOURCE_DATE_EPOCH environment variable is set, output files without a Last-Modified time, and the directories containing them, are given its time so that builds are reproducible.
+package main
+
+import (
+	"flag"
+	"iter"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"sync/atomic"
+
+	"4d63.com/static"
+	"4d63.com/static/discover"
+	"4d63.com/static/remote"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	options := static.DefaultOptions
+
+	fs := flag.NewFlagSet("static", flag.ContinueOnError)
+	fs.StringVar(&options.OutputDir, "output", options.OutputDir, "The directory where files will be written.")
+	fs.IntVar(&options.Concurrency, "concurrency", options.Concurrency, "The number of paths built concurrently.")
+	fs.StringVar(&options.DirFilename, "dir-filename", options.DirFilename, "The filename to use when saving directory paths.")
+	pluginPath := fs.String("plugin", "", "A Go plugin exporting the Handler to build.")
+	url := fs.String("url", "", "The base URL of a running server to build, e.g. http://localhost:8080.")
+	timeout := fs.Duration("timeout", remote.DefaultOptions.Timeout, "The time limit for each request to the -url server.")
+	pathsFile := fs.String("paths", "", "A file listing the paths to build, one per line, or - for stdin.")
+	sitemap := fs.String("sitemap", "", "The path of a sitemap served by the handler listing the paths to build, e.g. /sitemap.xml.")
+	origin := fs.String("origin", "", "The origin of the locations in the sitemap, e.g. https://example.com.")
+	err := fs.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	options.ModTime, err = static.SourceDateEpoch()
+	if err != nil {
+		log.Println(err)
+		return 2
+	}
+
+	if *sitemap != "" && *origin == "" {
+		log.Println("-origin must be given with -sitemap.")
+		return 2
+	}
+
+	var handler http.Handler
+	var pluginPaths []string
+	switch {
+	case *pluginPath != "" && *url != "":
+		log.Println("Only one of -plugin and -url can be given.")
+		return 2
+	case *pluginPath != "":
+		handler, pluginPaths, err = loadPlugin(*pluginPath)
+	case *url != "":
+		remoteOptions := remote.DefaultOptions
+		remoteOptions.Timeout = *timeout
+		remoteOptions.MaxIdleConnsPerHost = options.Concurrency
+		handler, err = remote.NewHandler(*url, remoteOptions)
+	default:
+		log.Println("One of -plugin or -url must be given.")
+		fs.Usage()
+		return 2
+	}
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+
+	var paths iter.Seq[string]
+	var pathsErr error
+	switch {
+	case *pathsFile != "":
+		f := os.Stdin
+		if *pathsFile != "-" {
+			f, err = os.Open(*pathsFile)
+			if err != nil {
+				log.Println(err)
+				return 1
+			}
+			defer f.Close()
+		}
+		paths = readPaths(f, &pathsErr)
+	case *sitemap != "":
+		sitemapPaths, err := discover.Sitemap(handler, *sitemap, *origin)
+		if err != nil {
+			log.Println(err)
+			return 1
+		}
+		paths = slices.Values(sitemapPaths)
+	case pluginPaths != nil:
+		paths = slices.Values(pluginPaths)
+	default:
+		paths = slices.Values([]string{"/"})
+	}
+
+	var failed atomic.Bool
+	err = static.BuildSeq(options, handler, paths, func(e static.Event) {
+		log.Println(e)
+		if e.Error != nil {
+			failed.Store(true)
+		}
+	})
+	if err == nil {
+		err = pathsErr
+	}
+	if err != nil {
+		log.Println(err)
+		return 1
+	}
+	if failed.Load() {
+		return 1
+	}
+	return 0
+}
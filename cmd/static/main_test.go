@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunURL(t *testing.T) {
+	t.Log("When a server is running that responds to /hello/* with Hello <path>!")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/hello/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	t.Log("And a paths file lists paths to build, with a comment and a blank line.")
+	tempDir, _ := ioutil.TempDir("", "")
+	pathsFile := filepath.Join(tempDir, "paths.txt")
+	ioutil.WriteFile(pathsFile, []byte("# pages\n/hello/go\n\n/hello/world\n"), 0644)
+	outputDir := filepath.Join(tempDir, "build")
+
+	t.Log("Expect run to build each path from the server into the output dir and succeed.")
+	code := run([]string{"-url", server.URL, "-paths", pathsFile, "-output", outputDir, "-concurrency", "2"})
+	if code != 0 {
+		t.Errorf("run() => %d, expected 0", code)
+	}
+	for _, name := range []string{"go", "world"} {
+		outputFilePath := filepath.Join(outputDir, "hello", name)
+		expected := fmt.Sprintf("Hello %s!", name)
+		contents, err := ioutil.ReadFile(outputFilePath)
+		if err != nil || string(contents) != expected {
+			t.Errorf("Contents of %s => %s, %v, expected %s", outputFilePath, contents, err, expected)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		args         []string
+		expectedCode int
+	}{
+		{[]string{}, 2},
+		{[]string{"-plugin", "app.so", "-url", "http://localhost"}, 2},
+		{[]string{"-url", "localhost"}, 1},
+		{[]string{"-plugin", "does-not-exist.so"}, 1},
+		{[]string{"-url", "http://localhost", "-paths", "does-not-exist.txt"}, 1},
+		{[]string{"-url", "http://localhost", "-sitemap", "/sitemap.xml"}, 2},
+	}
+
+	for _, test := range tests {
+		code := run(test.args)
+		if code == test.expectedCode {
+			t.Logf("run(%#v) => %d", test.args, code)
+		} else {
+			t.Errorf("run(%#v) => %d, expected %d", test.args, code, test.expectedCode)
+		}
+	}
+}
+
+func TestReadPaths(t *testing.T) {
+	input := "/\n  /about  \n# comment\n\n/posts/1\n"
+	expected := []string{"/", "/about", "/posts/1"}
+
+	var err error
+	var paths []string
+	for path := range readPaths(strings.NewReader(input), &err) {
+		paths = append(paths, path)
+	}
+	if err != nil || strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Errorf("readPaths(%#v) => %#v, %v, expected %#v, nil", input, paths, err, expected)
+	}
+}
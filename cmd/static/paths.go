@@ -0,0 +1,25 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"iter"
+	"strings"
+)
+
+// readPaths returns a sequence of the paths listed in the reader, one per line, skipping blank lines and lines starting with #. If reading fails the sequence ends early and the error is stored in err.
+func readPaths(r io.Reader, err *error) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		s := bufio.NewScanner(r)
+		for s.Scan() {
+			path := strings.TrimSpace(s.Text())
+			if path == "" || strings.HasPrefix(path, "#") {
+				continue
+			}
+			if !yield(path) {
+				return
+			}
+		}
+		*err = s.Err()
+	}
+}
@@ -0,0 +1,49 @@
+//go:build (linux || darwin || freebsd) && cgo
+// +build linux darwin freebsd
+// +build cgo
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"plugin"
+)
+
+// loadPlugin opens the Go plugin at the path and returns the Handler it exports, and the Paths it exports if any.
+func loadPlugin(path string) (http.Handler, []string, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to open plugin %s: %v", path, err)
+	}
+
+	s, err := p.Lookup("Handler")
+	if err != nil {
+		return nil, nil, fmt.Errorf("Plugin %s does not export a Handler: %v", path, err)
+	}
+	var handler http.Handler
+	switch s := s.(type) {
+	case *http.Handler:
+		handler = *s
+	case func() http.Handler:
+		handler = s()
+	default:
+		return nil, nil, fmt.Errorf("Plugin %s exports a Handler of unsupported type %T", path, s)
+	}
+	if handler == nil {
+		return nil, nil, fmt.Errorf("Plugin %s exports a nil Handler", path)
+	}
+
+	s, err = p.Lookup("Paths")
+	if err != nil {
+		return handler, nil, nil
+	}
+	switch s := s.(type) {
+	case *[]string:
+		return handler, *s, nil
+	case func() []string:
+		return handler, s(), nil
+	default:
+		return nil, nil, fmt.Errorf("Plugin %s exports Paths of unsupported type %T", path, s)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd) || !cgo
+// +build !linux,!darwin,!freebsd !cgo
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// loadPlugin returns an error because Go plugins are not supported on this platform.
+func loadPlugin(path string) (http.Handler, []string, error) {
+	return nil, nil, fmt.Errorf("Unable to open plugin %s: plugins are not supported on this platform", path)
+}
@@ -0,0 +1,160 @@
+package static
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Collision is how a path is built when its output file collides with the output file of another path, either because one needs the file as a directory, such as /docs and /docs/, or because they differ only in case, such as /About and /about, and would overwrite each other on a case-insensitive filesystem.
+type Collision int
+
+const (
+	// CollisionFail fails the colliding path with a CollisionError.
+	CollisionFail Collision = iota
+	// CollisionSkip does not build the colliding path, and reports the collision as a warning.
+	CollisionSkip
+	// CollisionDirIndex writes a path whose output file is needed as a directory to the DirFilename in that directory. e.g. /docs is written to docs/index.html. Other collisions fail with a CollisionError.
+	CollisionDirIndex
+)
+
+// CollisionError is the error for a path whose output file collides with the output file of another path.
+type CollisionError struct {
+	// The path that collides.
+	Path string
+	// The output file of the path.
+	OutputPath string
+	// The output file of the other path that the output file collides with.
+	With string
+	// How the output files collide. e.g. is a directory of
+	Reason string
+}
+
+// Error returns the collision as a string.
+func (e CollisionError) Error() string {
+	return fmt.Sprintf("Output file %s for path %s %s %s", e.OutputPath, e.Path, e.Reason, e.With)
+}
+
+// collisionDetector claims the output file of each path being built, so that output files that collide are detected regardless of the order paths are built in.
+type collisionDetector struct {
+	mutex       sync.Mutex
+	collision   Collision
+	dirFilename string
+	outputDir   string
+	ignoreCase  bool
+	// The output files claimed, keyed by their folded output path.
+	files map[string]string
+	// The directories needed by output files, keyed by their folded path, and the first output file needing each.
+	dirs map[string]string
+	// The result of claiming each output path.
+	claimed map[string]claimedOutputPath
+}
+
+type claimedOutputPath struct {
+	outputPath string
+	warning    string
+	err        error
+}
+
+// newCollisionDetector returns a collisionDetector that already knows the directories needed by the output paths, so that a path whose output file is needed as a directory collides even if it is built first.
+func newCollisionDetector(o Options, outputPaths []string) *collisionDetector {
+	c := &collisionDetector{
+		collision:   o.Collision,
+		dirFilename: o.DirFilename,
+		outputDir:   o.OutputDir,
+		ignoreCase:  !o.CaseSensitive,
+		files:       map[string]string{},
+		dirs:        map[string]string{},
+		claimed:     map[string]claimedOutputPath{},
+	}
+	for _, outputPath := range outputPaths {
+		c.addDirs(outputPath)
+	}
+	return c
+}
+
+func (c *collisionDetector) fold(outputPath string) string {
+	if c.ignoreCase {
+		return strings.ToLower(outputPath)
+	}
+	return outputPath
+}
+
+// ancestors calls f with each directory above the output path, up to the OutputDir, stopping if f returns false.
+func (c *collisionDetector) ancestors(outputPath string, f func(dir string) bool) {
+	for dir := filepath.Dir(outputPath); len(dir) > len(c.outputDir) && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if !f(dir) {
+			return
+		}
+	}
+}
+
+func (c *collisionDetector) addDirs(outputPath string) {
+	c.ancestors(outputPath, func(dir string) bool {
+		key := c.fold(dir)
+		if _, ok := c.dirs[key]; !ok {
+			c.dirs[key] = outputPath
+		}
+		return true
+	})
+}
+
+// claim the output path for the path, returning the output path to write to, which is empty if the path is to be skipped, and a warning if the collision was resolved. Claiming the same output path again returns the same result. If remember is false, the output path is checked against the output paths claimed but is not claimed itself, so that nothing is held for it.
+func (c *collisionDetector) claim(path, outputPath string, remember bool) (string, string, error) {
+	if c == nil {
+		return outputPath, "", nil
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	r, ok := c.claimed[outputPath]
+	if !ok {
+		r = c.resolve(path, outputPath, remember)
+		if remember {
+			c.claimed[outputPath] = r
+		}
+	}
+	return r.outputPath, r.warning, r.err
+}
+
+func (c *collisionDetector) resolve(path, outputPath string, remember bool) claimedOutputPath {
+	key := c.fold(outputPath)
+
+	if with, ok := c.dirs[key]; ok {
+		if c.collision == CollisionDirIndex {
+			r := c.resolve(path, filepath.Join(outputPath, c.dirFilename), remember)
+			if r.err == nil && r.warning == "" {
+				r.warning = fmt.Sprintf("output file %s is a directory of %s, written to %s", outputPath, with, r.outputPath)
+			}
+			return r
+		}
+		return c.collide(CollisionError{path, outputPath, with, "is a directory of"})
+	}
+
+	var file string
+	c.ancestors(outputPath, func(dir string) bool {
+		file = c.files[c.fold(dir)]
+		return file == ""
+	})
+	if file != "" {
+		return c.collide(CollisionError{path, outputPath, file, "needs as a directory the output file"})
+	}
+
+	if with, ok := c.files[key]; ok && with != outputPath {
+		return c.collide(CollisionError{path, outputPath, with, "differs only in case from"})
+	}
+
+	if remember {
+		c.files[key] = outputPath
+		c.addDirs(outputPath)
+	}
+	return claimedOutputPath{outputPath: outputPath}
+}
+
+func (c *collisionDetector) collide(err CollisionError) claimedOutputPath {
+	if c.collision == CollisionSkip {
+		return claimedOutputPath{warning: fmt.Sprintf("skipped because output file %s %s %s", err.OutputPath, err.Reason, err.With)}
+	}
+	return claimedOutputPath{err: err}
+}
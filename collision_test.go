@@ -0,0 +1,149 @@
+package static_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"4d63.com/static"
+)
+
+func TestBuildCollisions(t *testing.T) {
+	t.Log("When a Handler is defined to respond with the path.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})
+
+	t.Log("And the paths /docs and /docs/a collide because docs is a file and a directory, and /About and /about collide because they differ only in case.")
+	paths := []string{"/docs", "/docs/a", "/About", "/about"}
+
+	tests := []struct {
+		collision       static.Collision
+		caseSensitive   bool
+		expectedReasons map[string]string
+		expectedFiles   map[string]string
+		expectedWarning map[string]string
+	}{
+		{
+			collision:       static.CollisionFail,
+			expectedReasons: map[string]string{"/docs": "is a directory of", "/about": "differs only in case from"},
+			expectedFiles:   map[string]string{"docs/a": "/docs/a", "About": "/About"},
+		},
+		{
+			collision:       static.CollisionFail,
+			caseSensitive:   true,
+			expectedReasons: map[string]string{"/docs": "is a directory of"},
+			expectedFiles:   map[string]string{"docs/a": "/docs/a", "About": "/About", "about": "/about"},
+		},
+		{
+			collision:       static.CollisionSkip,
+			expectedFiles:   map[string]string{"docs/a": "/docs/a", "About": "/About"},
+			expectedWarning: map[string]string{"/docs": "skipped because", "/about": "skipped because"},
+		},
+		{
+			collision:       static.CollisionDirIndex,
+			expectedReasons: map[string]string{"/about": "differs only in case from"},
+			expectedFiles:   map[string]string{"docs/index.html": "/docs", "docs/a": "/docs/a", "About": "/About"},
+			expectedWarning: map[string]string{"/docs": "is a directory of"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("And Options are defined with defaults, an OutputDir that does not exist, a Collision of %d, and CaseSensitive %v.", test.collision, test.caseSensitive)
+		options := static.DefaultOptions
+		tempDir, _ := ioutil.TempDir("", "")
+		options.OutputDir = filepath.Join(tempDir, "build")
+		options.Collision = test.collision
+		options.CaseSensitive = test.caseSensitive
+
+		t.Log("Expect Build to resolve each collision the same way regardless of the order paths are built in.")
+		var mutex sync.Mutex
+		events := map[string]static.Event{}
+		static.Build(options, handler, paths, func(e static.Event) {
+			t.Logf("Event received => %v", e)
+			mutex.Lock()
+			defer mutex.Unlock()
+			events[e.Path] = e
+		})
+
+		for _, path := range paths {
+			e := events[path]
+			var collisionErr static.CollisionError
+			reason := test.expectedReasons[path]
+			if reason == "" {
+				if e.Error != nil {
+					t.Errorf("Event for %s => %v, expected no error", path, e)
+				}
+			} else if !errors.As(e.Error, &collisionErr) || collisionErr.Reason != reason {
+				t.Errorf("Event for %s => %v, expected a CollisionError with Reason %q", path, e, reason)
+			}
+			if warning := test.expectedWarning[path]; !strings.Contains(e.Warning, warning) || (warning == "") != (e.Warning == "") {
+				t.Errorf("Event for %s => %v, expected a Warning containing %q", path, e, warning)
+			}
+		}
+
+		files := map[string]string{}
+		filepath.Walk(options.OutputDir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				rel, _ := filepath.Rel(options.OutputDir, path)
+				contents, _ := ioutil.ReadFile(path)
+				files[filepath.ToSlash(rel)] = string(contents)
+			}
+			return nil
+		})
+		if !reflect.DeepEqual(files, test.expectedFiles) {
+			t.Errorf("Files => %v, expected %v", files, test.expectedFiles)
+		}
+	}
+}
+
+func TestBuildSourceCollisions(t *testing.T) {
+	t.Log("When a Handler is defined to respond with the path.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})
+
+	tests := []struct {
+		trackSourcePaths bool
+		expectedFailed   []string
+	}{
+		{true, []string{"/About", "/docs"}},
+		{false, []string{"/docs"}},
+	}
+
+	for _, test := range tests {
+		t.Logf("And Options are defined with defaults, a Concurrency of 1, an OutputDir that does not exist, and TrackSourcePaths %v.", test.trackSourcePaths)
+		options := static.DefaultOptions
+		options.Concurrency = 1
+		tempDir, _ := ioutil.TempDir("", "")
+		options.OutputDir = filepath.Join(tempDir, "build")
+		options.TrackSourcePaths = test.trackSourcePaths
+
+		t.Log("And a group lists /docs/, and has a Source yielding /docs, /about and /About.")
+		group := static.PathGroup{Paths: []string{"/docs/"}, Source: slices.Values([]string{"/docs", "/about", "/About"})}
+
+		t.Logf("Expect BuildGroups to fail %v, detecting collisions of paths from the Source with listed paths, and with each other only if they are tracked.", test.expectedFailed)
+		var mutex sync.Mutex
+		var failed []string
+		static.BuildGroups(options, handler, []static.PathGroup{group}, func(e static.Event) {
+			t.Logf("Event received => %v", e)
+			mutex.Lock()
+			defer mutex.Unlock()
+			var collisionErr static.CollisionError
+			if errors.As(e.Error, &collisionErr) {
+				failed = append(failed, e.Path)
+			}
+		})
+		slices.Sort(failed)
+		if !reflect.DeepEqual(failed, test.expectedFailed) {
+			t.Errorf("Failed => %v, expected %v", failed, test.expectedFailed)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package static
+
+import (
+	"sync"
+	"time"
+)
+
+// AdaptiveConcurrency for configuring Build to grow and shrink the number of paths built concurrently based on the latency and error rate of the handler.
+type AdaptiveConcurrency struct {
+	// The lowest number of paths built concurrently, and the number Build starts with. Values less than 1 are treated as 1.
+	MinConcurrency int
+	// The highest number of paths built concurrently. Zero means the Options Concurrency.
+	MaxConcurrency int
+	// The average time to build a path above which the concurrency is reduced. Zero means latency is ignored.
+	TargetLatency time.Duration
+	// The fraction of paths, between 0 and 1, that can fail or return 429 or 5xx status codes before the concurrency is reduced.
+	MaxErrorRate float64
+	// How often the concurrency is adjusted. Zero means every second.
+	Interval time.Duration
+}
+
+// concurrencyController limits the number of paths built concurrently, adjusting the limit every interval, increasing it by one while builds are healthy and halving it when they are not.
+type concurrencyController struct {
+	min, max      int
+	targetLatency time.Duration
+	maxErrorRate  float64
+	interval      time.Duration
+	eh            EventHandler
+
+	mutex       sync.Mutex
+	cond        *sync.Cond
+	limit       int
+	active      int
+	windowStart time.Time
+	builds      int
+	failures    int
+	latency     time.Duration
+}
+
+// newConcurrencyController returns a controller for the Options AdaptiveConcurrency, or nil if the Options do not have one.
+func newConcurrencyController(o Options, eh EventHandler) *concurrencyController {
+	a := o.AdaptiveConcurrency
+	if a == nil {
+		return nil
+	}
+
+	c := &concurrencyController{
+		min:           a.MinConcurrency,
+		max:           a.MaxConcurrency,
+		targetLatency: a.TargetLatency,
+		maxErrorRate:  a.MaxErrorRate,
+		interval:      a.Interval,
+		eh:            eh,
+	}
+	if c.min < 1 {
+		c.min = 1
+	}
+	if c.max == 0 {
+		c.max = o.Concurrency
+	}
+	if c.max < c.min {
+		c.max = c.min
+	}
+	if c.interval == 0 {
+		c.interval = time.Second
+	}
+	c.cond = sync.NewCond(&c.mutex)
+	c.limit = c.min
+	c.windowStart = time.Now()
+	return c
+}
+
+// workers returns the number of workers needed to build at the highest concurrency.
+func (c *concurrencyController) workers(o Options) int {
+	if c == nil {
+		return o.Concurrency
+	}
+	return c.max
+}
+
+// start reports the starting concurrency to the EventHandler.
+func (c *concurrencyController) start() {
+	if c == nil {
+		return
+	}
+	c.eh(Event{Action: CONCURRENCY, Concurrency: c.limit})
+}
+
+// acquire blocks until a path can be built within the current concurrency limit.
+func (c *concurrencyController) acquire() {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for c.active >= c.limit {
+		c.cond.Wait()
+	}
+	c.active++
+}
+
+// release frees the slot taken by acquire.
+func (c *concurrencyController) release() {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.active--
+	c.cond.Signal()
+}
+
+// record the result of building a path, adjusting the concurrency limit if the interval has passed.
+func (c *concurrencyController) record(latency time.Duration, statusCode int, err error) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	c.builds++
+	c.latency += latency
+	if err != nil || statusCode == 429 || statusCode >= 500 {
+		c.failures++
+	}
+
+	if time.Since(c.windowStart) < c.interval {
+		c.mutex.Unlock()
+		return
+	}
+
+	limit := c.limit
+	errorRate := float64(c.failures) / float64(c.builds)
+	averageLatency := c.latency / time.Duration(c.builds)
+	if errorRate > c.maxErrorRate || (c.targetLatency > 0 && averageLatency > c.targetLatency) {
+		limit /= 2
+	} else {
+		limit++
+	}
+	if limit < c.min {
+		limit = c.min
+	}
+	if limit > c.max {
+		limit = c.max
+	}
+
+	changed := limit != c.limit
+	c.limit = limit
+	c.windowStart = time.Now()
+	c.builds = 0
+	c.failures = 0
+	c.latency = 0
+	c.cond.Broadcast()
+	c.mutex.Unlock()
+
+	if changed {
+		c.eh(Event{Action: CONCURRENCY, Concurrency: limit})
+	}
+}
+
+// rateLimiter is a token bucket that limits the rate that paths are started.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mutex  sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter returns a limiter for the Options RateLimit, or nil if the Options do not have one.
+func newRateLimiter(o Options) *rateLimiter {
+	if o.RateLimit <= 0 {
+		return nil
+	}
+	burst := float64(o.RateBurst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{rate: o.RateLimit, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait blocks until a token is available and takes it.
+func (r *rateLimiter) wait() {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	now := time.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+	r.tokens--
+	var delay time.Duration
+	if r.tokens < 0 {
+		delay = time.Duration(-r.tokens / r.rate * float64(time.Second))
+	}
+	r.mutex.Unlock()
+
+	time.Sleep(delay)
+}
@@ -0,0 +1,167 @@
+package static_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"4d63.com/static"
+)
+
+func TestBuildRateLimit(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /* and response with Hello <path>!")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/hello/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+	})
+
+	t.Log("And Options are defined with defaults, a RateLimit of 100 paths per second with a RateBurst of 1 and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	options.RateLimit = 100
+	options.RateBurst = 1
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And there are 6 paths to build.")
+	paths := []string{"/hello/1", "/hello/2", "/hello/3", "/hello/4", "/hello/5", "/hello/6"}
+
+	t.Log("Expect Build to take at least 50ms, because only the first path is built without waiting.")
+	start := time.Now()
+	static.Build(options, handler, paths, nil)
+	elapsed := time.Since(start)
+	t.Logf("Build() took %s", elapsed)
+	if elapsed < 50*time.Millisecond {
+		t.Errorf("Build() took %s, expected at least 50ms", elapsed)
+	}
+}
+
+func TestBuildRateLimitLocales(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /* and response with Hello <path>!")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+	})
+
+	t.Log("And Options are defined with defaults, a RateLimit of 5 paths per second with a RateBurst of 1, the Locales en, fr and de, and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	options.RateLimit = 5
+	options.RateBurst = 1
+	options.Locales = &static.Locales{Locales: []string{"en", "fr", "de"}, Default: "en"}
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And there are 2 paths to build.")
+	paths := []string{"/hello/1", "/hello/2"}
+
+	t.Log("Expect Build to wait once for the second path, taking at least 150ms, and not once for each locale, which would take at least 1s.")
+	start := time.Now()
+	static.Build(options, handler, paths, nil)
+	elapsed := time.Since(start)
+	t.Logf("Build() took %s", elapsed)
+	if elapsed < 150*time.Millisecond || elapsed >= 600*time.Millisecond {
+		t.Errorf("Build() took %s, expected at least 150ms and less than 600ms", elapsed)
+	}
+}
+
+func TestBuildAdaptiveConcurrency(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /* and response with Hello <path>! and records how many requests it is serving at once.")
+	var mutex sync.Mutex
+	serving, maxServing := 0, 0
+	handler := http.NewServeMux()
+	handler.HandleFunc("/hello/", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		serving++
+		if serving > maxServing {
+			maxServing = serving
+		}
+		mutex.Unlock()
+		time.Sleep(time.Millisecond)
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+		mutex.Lock()
+		serving--
+		mutex.Unlock()
+	})
+
+	t.Log("And Options are defined with defaults, AdaptiveConcurrency from 1 to 3 adjusted after every path, and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	options.AdaptiveConcurrency = &static.AdaptiveConcurrency{MinConcurrency: 1, MaxConcurrency: 3, Interval: time.Nanosecond}
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And there are multiple paths to build.")
+	var paths []string
+	for i := 0; i < 20; i++ {
+		paths = append(paths, fmt.Sprintf("/hello/%d", i))
+	}
+
+	t.Log("Expect Build to report the concurrency growing from 1 to 3, and never serve more than 3 paths at once.")
+	var concurrencies []int
+	builds := 0
+	static.Build(options, handler, paths, func(e static.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch e.Action {
+		case static.CONCURRENCY:
+			t.Logf("Event received => %v", e)
+			concurrencies = append(concurrencies, e.Concurrency)
+		case static.BUILD:
+			builds++
+		}
+	})
+
+	if builds != len(paths) {
+		t.Errorf("Number of build events received => %d, expected %d", builds, len(paths))
+	}
+	expectedConcurrencies := []int{1, 2, 3}
+	if fmt.Sprint(concurrencies) != fmt.Sprint(expectedConcurrencies) {
+		t.Errorf("Concurrencies reported => %v, expected %v", concurrencies, expectedConcurrencies)
+	}
+	if maxServing > 3 {
+		t.Errorf("Paths served at once => %d, expected at most 3", maxServing)
+	}
+}
+
+func TestBuildAdaptiveConcurrencyShrinksOnErrors(t *testing.T) {
+	t.Log("When a Handler is defined to respond with 200 OK for the first paths and 503 Service Unavailable after.")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/ok/", func(w http.ResponseWriter, r *http.Request) {})
+	handler.HandleFunc("/unavailable/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(503)
+	})
+
+	t.Log("And Options are defined with defaults, a Concurrency of 1 and AdaptiveConcurrency from 1 to 4 adjusted after every path, and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	options.AdaptiveConcurrency = &static.AdaptiveConcurrency{MinConcurrency: 1, MaxConcurrency: 4, Interval: time.Nanosecond}
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And there are paths to build that succeed followed by paths that fail.")
+	paths := []string{"/ok/1", "/ok/2", "/ok/3"}
+	for i := 0; i < 20; i++ {
+		paths = append(paths, fmt.Sprintf("/unavailable/%d", i))
+	}
+
+	t.Log("Expect Build to report the concurrency growing and then shrinking back to 1.")
+	var mutex sync.Mutex
+	var concurrencies []int
+	static.Build(options, handler, paths, func(e static.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if e.Action == static.CONCURRENCY {
+			t.Logf("Event received => %v", e)
+			concurrencies = append(concurrencies, e.Concurrency)
+		}
+	})
+
+	if len(concurrencies) < 3 || concurrencies[len(concurrencies)-1] != 1 {
+		t.Errorf("Concurrencies reported => %v, expected growth then a return to 1", concurrencies)
+	}
+}
@@ -0,0 +1,131 @@
+package static
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Conditional is the policy for building paths whose output file already exists with conditional requests, so that handlers using http.ServeContent or checking the If-Modified-Since and If-None-Match headers can respond with 304 Not Modified and the output file is kept as is.
+type Conditional struct {
+	// The path in the OutputDir of a JSON file storing the ETag of each output file, read before the build to send If-None-Match, and written after the build. Empty means only If-Modified-Since is sent. e.g. /.etags.json
+	ETags string
+}
+
+// conditions returns the conditional request headers for the existing output file of the target, or nil if the target is not built conditionally or the output file does not exist.
+func (t buildTarget) conditions(outputPath string) http.Header {
+	if t.o.Conditional == nil {
+		return nil
+	}
+	fi, err := os.Stat(outputPath)
+	if err != nil || !fi.Mode().IsRegular() {
+		return nil
+	}
+
+	conditions := http.Header{}
+	conditions.Set("If-Modified-Since", fi.ModTime().UTC().Format(http.TimeFormat))
+	if etag := t.etags.get(outputPath); etag != "" {
+		conditions.Set("If-None-Match", etag)
+	}
+	return conditions
+}
+
+// built reports if the status code is for a path that was built, either written or kept as is because it was not modified.
+func built(statusCode int) bool {
+	return statusCode == http.StatusOK || statusCode == http.StatusNotModified
+}
+
+// etagStore holds the ETag of each output file, keyed by the output file relative to the OutputDir with forward slashes.
+type etagStore struct {
+	mutex     sync.Mutex
+	outputDir string
+	etags     map[string]string
+}
+
+// loadETags returns the etagStore read from the ETags file of the Options Conditional, or nil if the Options are not Conditional or do not store ETags. If the file cannot be read the EventHandler is called with the error and the store starts empty.
+func loadETags(o Options, eh EventHandler) *etagStore {
+	c := o.Conditional
+	if c == nil || c.ETags == "" {
+		return nil
+	}
+
+	s := &etagStore{outputDir: o.OutputDir, etags: map[string]string{}}
+	outputPath := filepath.Join(o.OutputDir, filepath.FromSlash(c.ETags))
+	data, err := os.ReadFile(outputPath)
+	if os.IsNotExist(err) {
+		return s
+	}
+	if err == nil {
+		err = json.Unmarshal(data, &s.etags)
+	}
+	if err != nil {
+		message := fmt.Sprintf("Unable to read ETags %s", outputPath)
+		eh(Event{Action: MANIFEST, Path: c.ETags, OutputPath: outputPath, Error: buildError{message, err}})
+		s.etags = map[string]string{}
+	}
+	return s
+}
+
+func (s *etagStore) key(outputPath string) string {
+	key, err := filepath.Rel(s.outputDir, outputPath)
+	if err != nil {
+		key = outputPath
+	}
+	return filepath.ToSlash(key)
+}
+
+func (s *etagStore) get(outputPath string) string {
+	if s == nil {
+		return ""
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.etags[s.key(outputPath)]
+}
+
+// record the ETag in the response header as the ETag of the output file, forgetting any previous ETag if the header has none.
+func (s *etagStore) record(outputPath string, header http.Header) {
+	if s == nil {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	key := s.key(outputPath)
+	if etag := header.Get("ETag"); etag != "" {
+		s.etags[key] = etag
+	} else {
+		delete(s.etags, key)
+	}
+}
+
+// write the ETags into the OutputDir, calling the EventHandler with the result.
+func (s *etagStore) write(o Options, eh EventHandler) {
+	if s == nil {
+		return
+	}
+
+	path := o.Conditional.ETags
+	outputPath := filepath.Join(o.OutputDir, filepath.FromSlash(path))
+	f, err := createFile(o, outputPath, path)
+	if err != nil {
+		eh(Event{Action: MANIFEST, Path: path, Error: err})
+		return
+	}
+	defer f.Close()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(s.etags)
+	if err != nil {
+		message := fmt.Sprintf("Unable to write ETags %s", outputPath)
+		eh(Event{Action: MANIFEST, Path: path, Error: buildError{message, err}})
+		return
+	}
+	err = setModTime(o, outputPath, path, nil)
+	eh(Event{Action: MANIFEST, Path: path, OutputPath: outputPath, Error: err})
+}
@@ -0,0 +1,121 @@
+package static_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"4d63.com/static"
+)
+
+func TestBuildConditional(t *testing.T) {
+	t.Log("When a Handler is defined to respond with http.ServeContent with a fixed modification time, and a body that can be changed.")
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	body := "Hello!"
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "page.html", modTime, strings.NewReader(body))
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and Conditional.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Conditional = &static.Conditional{}
+	outputFilePath := filepath.Join(options.OutputDir, "page")
+
+	t.Log("Expect the first BuildSingle to write the file with a modification time of the Last-Modified time.")
+	statusCode, _, err := static.BuildSingle(options, handler, "/page")
+	if statusCode != 200 || err != nil {
+		t.Fatalf("BuildSingle() => %d, %v, expected 200, nil", statusCode, err)
+	}
+	fi, err := os.Stat(outputFilePath)
+	if err != nil || !fi.ModTime().Equal(modTime) {
+		t.Errorf("Modification time of %s => %v, %v, expected %v", outputFilePath, fi, err, modTime)
+	}
+
+	t.Log("And when the body changes without the modification time changing.")
+	body = "Changed!"
+
+	t.Log("Expect the second BuildSingle to return 304 and keep the file as is.")
+	statusCode, _, err = static.BuildSingle(options, handler, "/page")
+	if statusCode != 304 || err != nil {
+		t.Fatalf("BuildSingle() => %d, %v, expected 304, nil", statusCode, err)
+	}
+	contents, err := ioutil.ReadFile(outputFilePath)
+	if err != nil || string(contents) != "Hello!" {
+		t.Errorf("Contents of %s => %s, %v, expected Hello!", outputFilePath, contents, err)
+	}
+
+	t.Log("And when the modification time changes.")
+	modTime = modTime.Add(time.Hour)
+
+	t.Log("Expect the third BuildSingle to return 200 and rewrite the file.")
+	statusCode, _, err = static.BuildSingle(options, handler, "/page")
+	if statusCode != 200 || err != nil {
+		t.Fatalf("BuildSingle() => %d, %v, expected 200, nil", statusCode, err)
+	}
+	contents, err = ioutil.ReadFile(outputFilePath)
+	if err != nil || string(contents) != "Changed!" {
+		t.Errorf("Contents of %s => %s, %v, expected Changed!", outputFilePath, contents, err)
+	}
+}
+
+func TestBuildConditionalETags(t *testing.T) {
+	t.Log("When a Handler is defined to respond with an ETag and http.ServeContent with no modification time.")
+	etag := `"v1"`
+	requests := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "page.html", time.Time{}, strings.NewReader("Hello "+etag))
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, a Concurrency of 1, and Conditional with ETags stored.")
+	options := static.DefaultOptions
+	options.Concurrency = 1
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Conditional = &static.Conditional{ETags: "/etags.json"}
+
+	t.Log("Expect the first Build to write the file and store its ETag.")
+	statusCodes := map[string]int{}
+	eh := func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		if e.Error != nil {
+			t.Errorf("Event error => %v, expected nil", e.Error)
+		}
+		statusCodes[e.Path] = e.StatusCode
+	}
+	static.Build(options, handler, []string{"/page"}, eh)
+	contents, err := ioutil.ReadFile(filepath.Join(options.OutputDir, "etags.json"))
+	etags := map[string]string{}
+	json.Unmarshal(contents, &etags)
+	if err != nil || etags["page"] != `"v1"` {
+		t.Errorf("ETags => %s, %v, expected page to be \"v1\"", contents, err)
+	}
+
+	t.Log("Expect the second Build to send the stored ETag and get 304.")
+	static.Build(options, handler, []string{"/page"}, eh)
+	if statusCodes["/page"] != 304 {
+		t.Errorf("StatusCode => %d, expected 304", statusCodes["/page"])
+	}
+
+	t.Log("And when the ETag changes.")
+	etag = `"v2"`
+
+	t.Log("Expect the third Build to rewrite the file.")
+	static.Build(options, handler, []string{"/page"}, eh)
+	outputFilePath := filepath.Join(options.OutputDir, "page")
+	contents, err = ioutil.ReadFile(outputFilePath)
+	if err != nil || string(contents) != `Hello "v2"` {
+		t.Errorf("Contents of %s => %s, %v, expected Hello \"v2\"", outputFilePath, contents, err)
+	}
+	if requests != 3 {
+		t.Errorf("Requests => %d, expected 3", requests)
+	}
+}
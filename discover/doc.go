@@ -0,0 +1,2 @@
+// Package discover finds the paths to build with `4d63.com/static` from the routes registered with a router.
+package discover // import "4d63.com/static/discover"
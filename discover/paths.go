@@ -0,0 +1,139 @@
+package discover
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// Values is a function that returns the values a wildcard in a pattern can take, given the pattern and the wildcard's name. Values are inserted into the path as is. Returning no values skips the pattern.
+type Values func(pattern, name string) []string
+
+// Paths returns the paths of the router's routes that can be built, in the order they were registered and without duplicates. Wildcards in patterns are expanded with values, which may be nil if no patterns contain wildcards. Patterns for methods other than GET are skipped, and hosts in patterns are ignored. Returns an error if a pattern is malformed.
+func Paths(r Router, values Values) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{}
+	for _, pattern := range r.Patterns() {
+		expanded, err := Expand(pattern, values)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range expanded {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// Seq returns a sequence of the same paths as Paths, for building with static.BuildSeq. Unlike Paths, a malformed pattern does not stop the sequence, because the paths before it may already have been built. It is skipped, and its error passed to errs, which may be nil if errors are to be ignored.
+func Seq(r Router, values Values, errs func(error)) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		seen := map[string]bool{}
+		for _, pattern := range r.Patterns() {
+			expanded, err := Expand(pattern, values)
+			if err != nil {
+				if errs != nil {
+					errs(err)
+				}
+				continue
+			}
+			for _, path := range expanded {
+				if seen[path] {
+					continue
+				}
+				seen[path] = true
+				if !yield(path) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Expand returns the paths matched by the pattern, with each wildcard replaced by each of its values. Patterns can be in the syntax of http.ServeMux, e.g. "GET example.com/posts/{id}", "/files/{path...}" and "/{$}", or use the wildcard syntax of other popular routers, e.g. "/posts/{id:[0-9]+}", "/posts/:id", "/files/*path" and "/files/*", where the name of the last is "*". Returns no paths for patterns for methods other than GET, and an error if the pattern is malformed.
+func Expand(pattern string, values Values) ([]string, error) {
+	p := pattern
+	if i := strings.IndexAny(p, " \t"); i >= 0 {
+		method := p[:i]
+		if method != "GET" {
+			return nil, nil
+		}
+		p = strings.TrimLeft(p[i:], " \t")
+	}
+	if i := strings.Index(p, "/"); i > 0 {
+		p = p[i:]
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("Pattern %q does not contain a path", pattern)
+	}
+
+	paths := []string{""}
+	segments := strings.Split(p[1:], "/")
+	for i, segment := range segments {
+		name, err := wildcard(pattern, segment)
+		if err != nil {
+			return nil, err
+		}
+		if i < len(segments)-1 && (name == "$" || strings.HasSuffix(segment, "...}")) {
+			return nil, fmt.Errorf("Pattern %q has a wildcard %q that is not the last segment", pattern, segment)
+		}
+
+		var options []string
+		switch {
+		case name == "$":
+			options = []string{""}
+		case name != "":
+			if values != nil {
+				options = values(pattern, name)
+			}
+			if len(options) == 0 {
+				return nil, nil
+			}
+		default:
+			options = []string{segment}
+		}
+
+		expanded := make([]string, 0, len(paths)*len(options))
+		for _, path := range paths {
+			for _, option := range options {
+				expanded = append(expanded, path+"/"+option)
+			}
+		}
+		paths = expanded
+	}
+	return paths, nil
+}
+
+// wildcard returns the name of the wildcard if the segment is one, otherwise an empty string.
+func wildcard(pattern, segment string) (string, error) {
+	switch {
+	case strings.HasPrefix(segment, "{"):
+		if !strings.HasSuffix(segment, "}") {
+			return "", fmt.Errorf("Pattern %q has an unclosed wildcard %q", pattern, segment)
+		}
+		name := segment[1 : len(segment)-1]
+		name = strings.TrimSuffix(name, "...")
+		if i := strings.Index(name, ":"); i >= 0 {
+			name = name[:i]
+		}
+		if name == "" {
+			return "", fmt.Errorf("Pattern %q has a wildcard without a name", pattern)
+		}
+		return name, nil
+	case strings.HasPrefix(segment, ":"):
+		name := segment[1:]
+		if name == "" {
+			return "", fmt.Errorf("Pattern %q has a wildcard without a name", pattern)
+		}
+		return name, nil
+	case strings.HasPrefix(segment, "*"):
+		if segment == "*" {
+			return "*", nil
+		}
+		return segment[1:], nil
+	}
+	return "", nil
+}
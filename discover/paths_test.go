@@ -0,0 +1,150 @@
+package discover_test
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"4d63.com/static/discover"
+)
+
+func TestExpand(t *testing.T) {
+	values := func(pattern, name string) []string {
+		switch name {
+		case "id":
+			return []string{"1", "2"}
+		case "path", "*":
+			return []string{"a/b.css"}
+		case "lang":
+			return []string{"en", "fr"}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		pattern  string
+		expected []string
+	}{
+		{"/", []string{"/"}},
+		{"/about", []string{"/about"}},
+		{"/docs/", []string{"/docs/"}},
+		{"/{$}", []string{"/"}},
+		{"GET /about", []string{"/about"}},
+		{"GET example.com/about", []string{"/about"}},
+		{"example.com/about", []string{"/about"}},
+		{"POST /about", nil},
+		{"/posts/{id}", []string{"/posts/1", "/posts/2"}},
+		{"/posts/{id}/{$}", []string{"/posts/1/", "/posts/2/"}},
+		{"/{lang}/posts/{id}", []string{"/en/posts/1", "/en/posts/2", "/fr/posts/1", "/fr/posts/2"}},
+		{"/files/{path...}", []string{"/files/a/b.css"}},
+		{"/posts/{id:[0-9]+}", []string{"/posts/1", "/posts/2"}},
+		{"/posts/:id", []string{"/posts/1", "/posts/2"}},
+		{"/files/*path", []string{"/files/a/b.css"}},
+		{"/files/*", []string{"/files/a/b.css"}},
+		{"/users/{user}", nil},
+	}
+
+	for _, test := range tests {
+		paths, err := discover.Expand(test.pattern, values)
+		if err == nil && reflect.DeepEqual(paths, test.expected) {
+			t.Logf("Expand(%#v) => %#v, %v", test.pattern, paths, err)
+		} else {
+			t.Errorf("Expand(%#v) => %#v, %v, want %#v, nil", test.pattern, paths, err, test.expected)
+		}
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	tests := []struct {
+		pattern     string
+		expectedErr string
+	}{
+		{"about", "does not contain a path"},
+		{"/posts/{id", "has an unclosed wildcard"},
+		{"/posts/{}", "has a wildcard without a name"},
+		{"/posts/:", "has a wildcard without a name"},
+		{"/{$}/x", "is not the last segment"},
+		{"/files/{path...}/x", "is not the last segment"},
+	}
+
+	for _, test := range tests {
+		paths, err := discover.Expand(test.pattern, nil)
+		if err != nil && strings.Contains(err.Error(), test.expectedErr) {
+			t.Logf("Expand(%#v) => %#v, %v", test.pattern, paths, err)
+		} else {
+			t.Errorf("Expand(%#v) => %#v, %v, want a %s error", test.pattern, paths, err, test.expectedErr)
+		}
+	}
+}
+
+func TestPathsFromMux(t *testing.T) {
+	t.Log("When a Mux has routes registered with static paths, wildcards, methods other than GET, and duplicate paths.")
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+	mux := discover.NewServeMux()
+	mux.HandleFunc("/{$}", handler)
+	mux.HandleFunc("GET /about", handler)
+	mux.HandleFunc("POST /contact", handler)
+	mux.Handle("/posts/{id}", http.HandlerFunc(handler))
+	mux.HandleFunc("GET /posts/1", handler)
+
+	t.Log("And the id wildcard has the values 1 and 2.")
+	values := func(pattern, name string) []string {
+		return []string{"1", "2"}
+	}
+
+	t.Log("Expect Paths to return each GET path once in the order registered.")
+	expected := []string{"/", "/about", "/posts/1", "/posts/2"}
+	paths, err := discover.Paths(mux, values)
+	if err != nil || !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Paths() => %#v, %v, want %#v, nil", paths, err, expected)
+	}
+
+	t.Log("Expect Seq to yield the same paths.")
+	var seqPaths []string
+	for path := range discover.Seq(mux, values, func(err error) {
+		t.Errorf("Seq() error => %v, want no errors", err)
+	}) {
+		seqPaths = append(seqPaths, path)
+	}
+	if !reflect.DeepEqual(seqPaths, expected) {
+		t.Errorf("Seq() => %#v, want %#v", seqPaths, expected)
+	}
+}
+
+func TestSeqErrors(t *testing.T) {
+	t.Log("When a third-party router lists its routes through a RouterFunc, including a malformed pattern between two valid ones.")
+	router := discover.RouterFunc(func() []string {
+		return []string{"GET /", "GET /posts/{id", "GET /about"}
+	})
+
+	t.Log("Expect Seq to yield the paths of the valid patterns, and pass the error of the malformed pattern to errs.")
+	var paths []string
+	var errs []error
+	for path := range discover.Seq(router, nil, func(err error) { errs = append(errs, err) }) {
+		paths = append(paths, path)
+	}
+	expected := []string{"/", "/about"}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Seq() => %#v, want %#v", paths, expected)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "has an unclosed wildcard") {
+		t.Errorf("Seq() errors => %v, want an unclosed wildcard error", errs)
+	}
+}
+
+func TestPathsFromRouterFunc(t *testing.T) {
+	t.Log("When a third-party router lists its routes through a RouterFunc.")
+	router := discover.RouterFunc(func() []string {
+		return []string{"GET /", "GET /posts/:id", "DELETE /posts/:id"}
+	})
+
+	t.Log("Expect Paths to return the GET paths with wildcards expanded.")
+	expected := []string{"/", "/posts/7"}
+	paths, err := discover.Paths(router, func(pattern, name string) []string {
+		return []string{"7"}
+	})
+	if err != nil || !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Paths() => %#v, %v, want %#v, nil", paths, err, expected)
+	}
+}
@@ -0,0 +1,71 @@
+package discover
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Router is implemented by routers that can list the patterns of the routes registered with them.
+type Router interface {
+	Patterns() []string
+}
+
+// RouterFunc is a function that lists the patterns of routes, for adapting third-party routers to a Router. e.g. for a router that can walk its routes:
+//
+//	discover.RouterFunc(func() []string {
+//		var patterns []string
+//		chi.Walk(r, func(method, route string, h http.Handler, m ...func(http.Handler) http.Handler) error {
+//			patterns = append(patterns, method+" "+route)
+//			return nil
+//		})
+//		return patterns
+//	})
+type RouterFunc func() []string
+
+// Patterns calls the function.
+func (f RouterFunc) Patterns() []string {
+	return f()
+}
+
+// Mux is a http.ServeMux that records the patterns registered with it, because http.ServeMux does not list them.
+type Mux struct {
+	*http.ServeMux
+
+	mutex    sync.Mutex
+	patterns []string
+}
+
+// NewServeMux returns a Mux wrapping a new http.ServeMux.
+func NewServeMux() *Mux {
+	return Wrap(http.NewServeMux())
+}
+
+// Wrap returns a Mux wrapping the http.ServeMux. Only patterns registered through the Mux are recorded.
+func Wrap(mux *http.ServeMux) *Mux {
+	return &Mux{ServeMux: mux}
+}
+
+// Handle registers the handler for the pattern with the http.ServeMux, and records the pattern.
+func (m *Mux) Handle(pattern string, handler http.Handler) {
+	m.ServeMux.Handle(pattern, handler)
+	m.record(pattern)
+}
+
+// HandleFunc registers the handler function for the pattern with the http.ServeMux, and records the pattern.
+func (m *Mux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.ServeMux.HandleFunc(pattern, handler)
+	m.record(pattern)
+}
+
+// Patterns returns the patterns registered, in the order they were registered.
+func (m *Mux) Patterns() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return append([]string(nil), m.patterns...)
+}
+
+func (m *Mux) record(pattern string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.patterns = append(m.patterns, pattern)
+}
@@ -0,0 +1,122 @@
+package discover
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+// sitemap is a sitemap or sitemap index as defined at https://www.sitemaps.org/protocol.html.
+type sitemap struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// Sitemap returns the paths listed in the sitemap served by the handler at the path, for building with static.Build so that the sitemap is the source of truth for what is built. Sitemap indexes are followed, and the paths of the sitemaps are included so that they are built too. Gzipped sitemaps are supported. Locations are included if they are relative or have the origin, e.g. https://example.com, and others are skipped. Returns an error if a sitemap cannot be served or parsed, or if it has an absolute location and the origin is empty.
+func Sitemap(h http.Handler, path, origin string) ([]string, error) {
+	o, err := url.Parse(origin)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse origin %s: %v", origin, err)
+	}
+
+	var paths []string
+	seen := map[string]bool{}
+	add := func(p string) bool {
+		if seen[p] {
+			return false
+		}
+		seen[p] = true
+		paths = append(paths, p)
+		return true
+	}
+
+	sitemaps := []string{path}
+	add(path)
+	for len(sitemaps) > 0 {
+		sitemapPath := sitemaps[0]
+		s, err := serveSitemap(h, sitemapPath)
+		if err != nil {
+			return nil, err
+		}
+		sitemaps = sitemaps[1:]
+
+		for _, loc := range s.URLs {
+			p, ok, err := sameOrigin(o, sitemapPath, loc.Loc)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				add(p)
+			}
+		}
+		for _, loc := range s.Sitemaps {
+			p, ok, err := sameOrigin(o, sitemapPath, loc.Loc)
+			if err != nil {
+				return nil, err
+			}
+			if ok && add(p) {
+				sitemaps = append(sitemaps, p)
+			}
+		}
+	}
+
+	return paths, nil
+}
+
+// serveSitemap calls the handler for the sitemap at the path and parses the response.
+func serveSitemap(h http.Handler, path string) (sitemap, error) {
+	r, err := http.NewRequest("GET", path, nil)
+	if err != nil {
+		return sitemap{}, fmt.Errorf("Unable to create http.Request for sitemap %s: %v", path, err)
+	}
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, r)
+	if rw.Code != http.StatusOK {
+		return sitemap{}, fmt.Errorf("Unable to get sitemap %s: status %d", path, rw.Code)
+	}
+
+	var body io.Reader = rw.Body
+	if bytes.HasPrefix(rw.Body.Bytes(), []byte{0x1f, 0x8b}) {
+		body, err = gzip.NewReader(rw.Body)
+		if err != nil {
+			return sitemap{}, fmt.Errorf("Unable to decompress sitemap %s: %v", path, err)
+		}
+	}
+
+	var s sitemap
+	err = xml.NewDecoder(body).Decode(&s)
+	if err != nil {
+		return sitemap{}, fmt.Errorf("Unable to parse sitemap %s: %v", path, err)
+	}
+	if s.XMLName.Local != "urlset" && s.XMLName.Local != "sitemapindex" {
+		return sitemap{}, fmt.Errorf("Unable to parse sitemap %s: unexpected element %s", path, s.XMLName.Local)
+	}
+	return s, nil
+}
+
+// sameOrigin returns the path of the location if it is relative or has the same scheme and host as the origin. Returns an error if the location is absolute and the origin is empty, because whether it is on the origin cannot be known.
+func sameOrigin(origin *url.URL, path, loc string) (string, bool, error) {
+	u, err := url.Parse(strings.TrimSpace(loc))
+	if err != nil {
+		return "", false, nil
+	}
+	if u.Host != "" && origin.Host == "" {
+		return "", false, fmt.Errorf("Unable to resolve absolute location %s in sitemap %s without an origin", loc, path)
+	}
+	u = origin.ResolveReference(u)
+	if u.Scheme != origin.Scheme || u.Host != origin.Host {
+		return "", false, nil
+	}
+	return u.RequestURI(), true, nil
+}
@@ -0,0 +1,100 @@
+package discover_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"4d63.com/static/discover"
+)
+
+func TestSitemap(t *testing.T) {
+	t.Log("When a Handler serves a sitemap index listing two sitemaps, one of them gzipped, and a sitemap on another origin.")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/sitemap-posts.xml</loc></sitemap>
+  <sitemap><loc>/sitemap-pages.xml.gz</loc></sitemap>
+  <sitemap><loc>https://other.example.com/sitemap.xml</loc></sitemap>
+</sitemapindex>`)
+	})
+	handler.HandleFunc("/sitemap-posts.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>
+      https://example.com/posts/1
+    </loc>
+  </url>
+  <url><loc>https://example.com/posts/2#comments</loc></url>
+  <url><loc>http://example.com/posts/3</loc></url>
+  <url><loc>https://other.example.com/posts/4</loc></url>
+</urlset>`)
+	})
+	handler.HandleFunc("/sitemap-pages.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		gw := gzip.NewWriter(w)
+		defer gw.Close()
+		fmt.Fprint(gw, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>https://example.com/</loc></url>
+  <url><loc>https://example.com/search?q=go</loc></url>
+  <url><loc>https://example.com/posts/1</loc></url>
+</urlset>`)
+	})
+
+	t.Log("Expect Sitemap to return the sitemaps and the pages on the origin, once each.")
+	expected := []string{
+		"/sitemap.xml",
+		"/sitemap-posts.xml",
+		"/sitemap-pages.xml.gz",
+		"/posts/1",
+		"/posts/2",
+		"/",
+		"/search?q=go",
+	}
+	paths, err := discover.Sitemap(handler, "/sitemap.xml", "https://example.com")
+	if err != nil || !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Sitemap() => %#v, %v, want %#v, nil", paths, err, expected)
+	}
+}
+
+func TestSitemapErrors(t *testing.T) {
+	handler := http.NewServeMux()
+	handler.HandleFunc("/invalid.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<urlset><url>`)
+	})
+	handler.HandleFunc("/html.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html></html>`)
+	})
+	handler.HandleFunc("/gzip.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bytes.Repeat([]byte{0x1f, 0x8b}, 2))
+	})
+	handler.HandleFunc("/absolute.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<urlset><url><loc>https://example.com/about</loc></url></urlset>`)
+	})
+
+	tests := []struct {
+		path        string
+		origin      string
+		expectedErr string
+	}{
+		{"/missing.xml", "https://example.com", "Unable to get sitemap /missing.xml: status 404"},
+		{"/invalid.xml", "https://example.com", "Unable to parse sitemap /invalid.xml"},
+		{"/html.xml", "https://example.com", "Unable to parse sitemap /html.xml: unexpected element html"},
+		{"/gzip.xml", "https://example.com", "Unable to decompress sitemap /gzip.xml"},
+		{"/absolute.xml", "", "Unable to resolve absolute location https://example.com/about in sitemap /absolute.xml without an origin"},
+	}
+
+	for _, test := range tests {
+		paths, err := discover.Sitemap(handler, test.path, test.origin)
+		if err != nil && strings.Contains(err.Error(), test.expectedErr) {
+			t.Logf("Sitemap(%#v) => %#v, %v", test.path, paths, err)
+		} else {
+			t.Errorf("Sitemap(%#v) => %#v, %v, want a %s error", test.path, paths, err, test.expectedErr)
+		}
+	}
+}
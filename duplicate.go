@@ -0,0 +1,110 @@
+package static
+
+import (
+	"fmt"
+	pathpkg "path"
+	"strings"
+	"sync"
+)
+
+// canonicalPath returns the path with its . and .. segments resolved, repeated slashes removed, and its fragment stripped, keeping any trailing slash and query string. e.g. /a/../b//c#d is /b/c
+func canonicalPath(path string) string {
+	if i := strings.IndexByte(path, '#'); i >= 0 {
+		path = path[:i]
+	}
+	path, query, hasQuery := strings.Cut(path, "?")
+	if strings.HasPrefix(path, "/") {
+		clean := pathpkg.Clean(path)
+		if strings.HasSuffix(path, "/") && clean != "/" {
+			clean += "/"
+		}
+		path = clean
+	}
+	if hasQuery {
+		path += "?" + query
+	}
+	return path
+}
+
+// duplicatePath is a path that was not scheduled because its canonical path had already been scheduled.
+type duplicatePath struct {
+	path      string
+	canonical string
+	// The host of the site of the path's group, or empty if the group has no site.
+	host string
+}
+
+// dedupe returns the canonical path of the path for the group, and false if it has already been scheduled, recording the duplicate. The path is remembered as scheduled if remember is true. Must be called with the scheduler mutex held.
+func (s *scheduler) dedupe(g *scheduledGroup, path string, remember bool) (string, bool) {
+	canonical := canonicalPath(path)
+	var host string
+	if g.site != nil {
+		host = g.site.Host
+	}
+	key := host + canonical
+	if s.scheduled[key] {
+		s.duplicates = append(s.duplicates, duplicatePath{path, canonical, host})
+		return canonical, false
+	}
+	if remember {
+		s.scheduled[key] = true
+	}
+	return canonical, true
+}
+
+// takeDuplicates returns the duplicate paths found since it was last called.
+func (s *scheduler) takeDuplicates() []duplicatePath {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	duplicates := s.duplicates
+	s.duplicates = nil
+	return duplicates
+}
+
+// reportDuplicates calls the EventHandler with a warning for each duplicate path found since it was last called.
+func (b *builder) reportDuplicates() {
+	for _, d := range b.scheduler.takeDuplicates() {
+		b.eh(Event{Action: DUPLICATE, Path: d.path, Host: d.host, Warning: fmt.Sprintf("duplicate of path %s", d.canonical)})
+	}
+}
+
+// fileLocks serializes writes to the same output file by targets being built concurrently.
+type fileLocks struct {
+	mutex sync.Mutex
+	locks map[string]*fileLock
+}
+
+type fileLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newFileLocks() *fileLocks {
+	return &fileLocks{locks: map[string]*fileLock{}}
+}
+
+// lock the output file, returning a function that unlocks it.
+func (l *fileLocks) lock(outputPath string) (unlock func()) {
+	if l == nil {
+		return func() {}
+	}
+	l.mutex.Lock()
+	fl := l.locks[outputPath]
+	if fl == nil {
+		fl = &fileLock{}
+		l.locks[outputPath] = fl
+	}
+	fl.refs++
+	l.mutex.Unlock()
+
+	fl.Lock()
+	return func() {
+		fl.Unlock()
+		l.mutex.Lock()
+		fl.refs--
+		if fl.refs == 0 {
+			delete(l.locks, outputPath)
+		}
+		l.mutex.Unlock()
+	}
+}
@@ -0,0 +1,173 @@
+package static_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"4d63.com/static"
+)
+
+func TestBuildDuplicates(t *testing.T) {
+	t.Log("When a Handler is defined to count the requests for each path and respond with the path.")
+	var mutex sync.Mutex
+	requests := map[string]int{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests[r.URL.Path]++
+		mutex.Unlock()
+		w.Write([]byte(r.URL.Path))
+	})
+
+	t.Log("And Options are defined with defaults and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+
+	t.Log("And the paths to build include /a three times, once as /a/../a, and /b twice, once with a fragment.")
+	paths := []string{"/a", "/a/../a", "/b", "/a", "/b#top"}
+
+	t.Log("Expect Build to build /a and /b once each, and report the other paths as duplicates with a warning.")
+	duplicates := map[string]string{}
+	static.Build(options, handler, paths, func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		mutex.Lock()
+		defer mutex.Unlock()
+		if e.Action == static.DUPLICATE {
+			duplicates[e.Path] += e.Warning + ";"
+		}
+	})
+	expectedRequests := map[string]int{"/a": 1, "/b": 1}
+	if !reflect.DeepEqual(requests, expectedRequests) {
+		t.Errorf("Requests => %v, expected %v", requests, expectedRequests)
+	}
+	expectedDuplicates := map[string]string{
+		"/a/../a": "duplicate of path /a;",
+		"/a":      "duplicate of path /a;",
+		"/b#top":  "duplicate of path /b;",
+	}
+	if !reflect.DeepEqual(duplicates, expectedDuplicates) {
+		t.Errorf("Duplicates => %v, expected %v", duplicates, expectedDuplicates)
+	}
+}
+
+func TestBuildSeqDuplicates(t *testing.T) {
+	t.Log("When a Handler is defined to respond with the path.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})
+
+	tests := []struct {
+		trackSourcePaths   bool
+		expectedBuilt      []string
+		expectedDuplicates []string
+	}{
+		{true, []string{"/a", "/b"}, []string{"//a"}},
+		{false, []string{"/a", "/a", "/b"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Logf("And Options are defined with defaults, an OutputDir that does not exist, and TrackSourcePaths %v.", test.trackSourcePaths)
+		options := static.DefaultOptions
+		tempDir, _ := ioutil.TempDir("", "")
+		options.OutputDir = filepath.Join(tempDir, "build")
+		options.TrackSourcePaths = test.trackSourcePaths
+
+		t.Logf("Expect BuildSeq to build %v when /a is yielded twice, and report %v as duplicates.", test.expectedBuilt, test.expectedDuplicates)
+		var mutex sync.Mutex
+		var built, duplicates []string
+		static.BuildSeq(options, handler, slices.Values([]string{"/a", "/b", "//a"}), func(e static.Event) {
+			t.Logf("Event received => %v", e)
+			mutex.Lock()
+			defer mutex.Unlock()
+			switch e.Action {
+			case static.BUILD:
+				built = append(built, e.Path)
+			case static.DUPLICATE:
+				duplicates = append(duplicates, e.Path)
+			}
+		})
+		slices.Sort(built)
+		if !reflect.DeepEqual(built, test.expectedBuilt) || !reflect.DeepEqual(duplicates, test.expectedDuplicates) {
+			t.Errorf("Built => %v, Duplicates => %v, expected %v, %v", built, duplicates, test.expectedBuilt, test.expectedDuplicates)
+		}
+	}
+}
+
+func TestBuildSameOutputFile(t *testing.T) {
+	t.Log("When a Handler is defined to respond with a large body made of the path repeated.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 1000; i++ {
+			fmt.Fprintf(w, "%s\n", r.URL.Path)
+		}
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and a PathMapper that maps every path to the same file.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.PathMapper = func(o static.Options, path string) (string, error) {
+		return "same", nil
+	}
+
+	paths := []string{}
+	for i := 0; i < 20; i++ {
+		paths = append(paths, fmt.Sprintf("/path/%d", i))
+	}
+
+	t.Log("Expect Build to serialize the writes so the file is the complete body of one of the paths.")
+	static.Build(options, handler, paths, nil)
+	contents, err := ioutil.ReadFile(filepath.Join(options.OutputDir, "same"))
+	if err != nil {
+		t.Fatalf("Error reading file => %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(contents), "\n"), "\n")
+	if len(lines) != 1000 {
+		t.Fatalf("Lines => %d, expected 1000", len(lines))
+	}
+	for _, line := range lines {
+		if line != lines[0] {
+			t.Fatalf("Line => %s, expected every line to be %s", line, lines[0])
+		}
+	}
+}
+
+func TestBuildSitesDuplicates(t *testing.T) {
+	t.Log("When a Handler is defined to respond with the path.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	})
+
+	t.Log("And Options are defined with defaults and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+
+	t.Log("And there are two sites with the same paths, each listing /a twice.")
+	sites := []static.Site{
+		{Host: "example.com", Paths: []string{"/a", "/./a"}},
+		{Host: "blog.example.com", Paths: []string{"/a", "//a"}, OutputDir: "blog"},
+	}
+
+	t.Log("Expect BuildSites to report one duplicate for each site, with the host of the site in the event.")
+	var mutex sync.Mutex
+	duplicates := map[string]string{}
+	static.BuildSites(options, handler, sites, func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		mutex.Lock()
+		defer mutex.Unlock()
+		if e.Action == static.DUPLICATE {
+			duplicates[e.Path] = e.Host
+		}
+	})
+	expected := map[string]string{"/./a": "example.com", "//a": "blog.example.com"}
+	if !reflect.DeepEqual(duplicates, expected) {
+		t.Errorf("Duplicates => %v, expected %v", duplicates, expected)
+	}
+}
@@ -0,0 +1,43 @@
+package static
+
+import (
+	"fmt"
+	"os"
+)
+
+// EmptyBody is what is done with the output file of a path whose response has no body, such as a 204 No Content or 304 Not Modified response, a response to a HEAD request, or a response the handler wrote nothing to.
+type EmptyBody int
+
+const (
+	// EmptyBodyWrite writes an empty output file.
+	EmptyBodyWrite EmptyBody = iota
+	// EmptyBodySkip writes no output file, removing any output file from a previous build.
+	EmptyBodySkip
+	// EmptyBodyPlaceholder writes the EmptyBodyPlaceholder of the Options to the output file.
+	EmptyBodyPlaceholder
+	// EmptyBodyError writes no output file, removing any output file from a previous build, and fails the path.
+	EmptyBodyError
+)
+
+// emptyBody applies the EmptyBody of the Options to the output file of a path whose response had no body. Returns the output path written to, or an empty string if no output file was written.
+func emptyBody(o Options, outputPath, path string, statusCode int) (string, error) {
+	switch o.EmptyBody {
+	case EmptyBodySkip, EmptyBodyError:
+		err := os.Remove(outputPath)
+		if err != nil && !os.IsNotExist(err) {
+			message := fmt.Sprintf("Unable to remove file %s for path %s", outputPath, path)
+			return "", buildError{message, err}
+		}
+		if o.EmptyBody == EmptyBodyError {
+			message := fmt.Sprintf("Response with status %d for path %s has no body", statusCode, path)
+			return "", buildError{message, nil}
+		}
+		return "", nil
+	case EmptyBodyPlaceholder:
+		err := writeFile(o, outputPath, path, o.EmptyBodyPlaceholder)
+		if err != nil {
+			return "", err
+		}
+	}
+	return outputPath, nil
+}
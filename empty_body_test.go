@@ -0,0 +1,98 @@
+package static_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"4d63.com/static"
+)
+
+func TestBuildSingleEmptyBody(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /gone with 204 No Content, and to other paths with Hello!")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(204)
+		}
+		w.Write([]byte("Hello!"))
+	})
+
+	tests := []struct {
+		emptyBody          static.EmptyBody
+		retry              bool
+		expectError        bool
+		expectedOutputPath bool
+		expectedContents   string
+	}{
+		{static.EmptyBodyWrite, false, false, true, ""},
+		{static.EmptyBodySkip, false, false, false, ""},
+		{static.EmptyBodyPlaceholder, false, false, true, "placeholder"},
+		{static.EmptyBodyError, false, true, false, ""},
+		{static.EmptyBodyWrite, true, false, true, ""},
+		{static.EmptyBodySkip, true, false, false, ""},
+		{static.EmptyBodyPlaceholder, true, false, true, "placeholder"},
+		{static.EmptyBodyError, true, true, false, ""},
+	}
+
+	for _, test := range tests {
+		t.Logf("And Options are defined with defaults, an OutputDir with a file from a previous build of /gone, an EmptyBody of %d and a placeholder, and a RetryPolicy %v.", test.emptyBody, test.retry)
+		options := static.DefaultOptions
+		tempDir, _ := ioutil.TempDir("", "")
+		options.OutputDir = filepath.Join(tempDir, "build")
+		options.EmptyBody = test.emptyBody
+		options.EmptyBodyPlaceholder = []byte("placeholder")
+		if test.retry {
+			options.Retry = &static.RetryPolicy{MaxAttempts: 2}
+		}
+		outputFilePath := filepath.Join(options.OutputDir, "gone")
+		os.MkdirAll(options.OutputDir, 0755)
+		ioutil.WriteFile(outputFilePath, []byte("previous"), 0644)
+
+		t.Log("Expect BuildSingle of /gone to report 204, and to apply the EmptyBody to the output file.")
+		statusCode, outputPath, err := static.BuildSingle(options, handler, "/gone")
+		t.Logf("BuildSingle() => %d, %q, %v", statusCode, outputPath, err)
+		if statusCode != 204 {
+			t.Errorf("StatusCode => %d, expected 204", statusCode)
+		}
+		if (err != nil) != test.expectError {
+			t.Errorf("Error => %v, expected error %v", err, test.expectError)
+		}
+		if (outputPath != "") != test.expectedOutputPath {
+			t.Errorf("OutputPath => %q, expected output path %v", outputPath, test.expectedOutputPath)
+		}
+		contents, err := ioutil.ReadFile(outputFilePath)
+		if test.expectedOutputPath {
+			if err != nil || string(contents) != test.expectedContents {
+				t.Errorf("Contents of %s => %q, %v, expected %q", outputFilePath, contents, err, test.expectedContents)
+			}
+		} else if !os.IsNotExist(err) {
+			t.Errorf("Reading %s => %v, expected the file to not exist", outputFilePath, err)
+		}
+	}
+}
+
+func TestBuildSingleHead(t *testing.T) {
+	t.Log("When a Handler is defined to respond with Hello!")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello!"))
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, a HEAD Request, and an EmptyBody of EmptyBodySkip.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Request = &static.RequestTemplate{Method: http.MethodHead}
+	options.EmptyBody = static.EmptyBodySkip
+
+	t.Log("Expect BuildSingle to report 200 OK, discard the body, and write no file.")
+	statusCode, outputPath, err := static.BuildSingle(options, handler, "/hello")
+	if statusCode != 200 || outputPath != "" || err != nil {
+		t.Errorf("BuildSingle() => %d, %q, %v, expected 200, \"\", nil", statusCode, outputPath, err)
+	}
+	_, err = os.Stat(filepath.Join(options.OutputDir, "hello"))
+	if !os.IsNotExist(err) {
+		t.Errorf("Stat() => %v, expected the file to not exist", err)
+	}
+}
@@ -0,0 +1,94 @@
+package static
+
+import (
+	"fmt"
+	"sync"
+)
+
+// AbortError is returned by Build when it stops building paths early because the error threshold defined in the Options was reached.
+type AbortError struct {
+	// The path that failed and caused the error threshold to be reached.
+	Path string
+	// The error that occurred building the path.
+	Err error
+	// The paths that were never built because Build stopped early.
+	Unbuilt []string
+}
+
+// Error returns the error that caused the abort, and the number of paths that were not built, as a string.
+func (e AbortError) Error() string {
+	return fmt.Sprintf("Build aborted by path %s, %d paths not built: %v", e.Path, len(e.Unbuilt), e.Err)
+}
+
+// Unwrap returns the error that caused the abort.
+func (e AbortError) Unwrap() error {
+	return e.Err
+}
+
+// errorThreshold counts the paths that fail to build, and signals on reached when the Options MaxErrors or MaxErrorPercent is reached. When the total number of paths is not known, indicated by a negative total, the percentage is of the paths built so far.
+type errorThreshold struct {
+	maxErrors       int
+	maxErrorPercent float64
+	total           int
+
+	mutex   sync.Mutex
+	built   int
+	errors  int
+	path    string
+	err     error
+	reached chan struct{}
+}
+
+func newErrorThreshold(o Options, total int) *errorThreshold {
+	return &errorThreshold{
+		maxErrors:       o.MaxErrors,
+		maxErrorPercent: o.MaxErrorPercent,
+		total:           total,
+		reached:         make(chan struct{}),
+	}
+}
+
+// record the result of building a path, and signal if it causes the threshold to be reached.
+func (t *errorThreshold) record(path string, err error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.built++
+	if err == nil || t.err != nil {
+		return
+	}
+
+	t.errors++
+	total := t.total
+	if total < 0 {
+		total = t.built
+	}
+	maxErrorsReached := t.maxErrors > 0 && t.errors >= t.maxErrors
+	maxErrorPercentReached := t.maxErrorPercent > 0 && total > 0 && float64(t.errors)*100/float64(total) >= t.maxErrorPercent
+	if maxErrorsReached || maxErrorPercentReached {
+		t.path = path
+		t.err = err
+		close(t.reached)
+	}
+}
+
+// isReached reports if the threshold has been reached without blocking.
+func (t *errorThreshold) isReached() bool {
+	select {
+	case <-t.reached:
+		return true
+	default:
+		return false
+	}
+}
+
+// abortError returns an AbortError if the threshold was reached, otherwise nil.
+func (t *errorThreshold) abortError(unbuilt []string) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.err == nil {
+		return nil
+	}
+	return AbortError{Path: t.path, Err: t.err, Unbuilt: unbuilt}
+}
@@ -0,0 +1,145 @@
+package static_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"4d63.com/static"
+)
+
+func TestBuildMaxErrors(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /* and response with Hello <path>!")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/hello/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+	})
+
+	t.Log("And Options are defined with defaults, a Concurrency of 1, a MaxErrors of 1 and an OutputDir.")
+	options := static.DefaultOptions
+	options.Concurrency = 1
+	options.MaxErrors = 1
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And a file exists at the same path as the OutputDir (a problem).")
+	f, _ := os.Create(options.OutputDir)
+	defer f.Close()
+
+	t.Log("And there are multiple paths to build.")
+	paths := []string{
+		"/hello/go",
+		"/hello/world",
+		"/hello/universe",
+		"/hello/galaxy",
+		"/hello/moon",
+	}
+
+	t.Log("Expect Build to stop after the first error and return an AbortError listing the paths that were never built.")
+	var built []string
+	err := static.Build(options, handler, paths, func(e static.Event) {
+		t.Logf("Event received => %#v", e)
+		built = append(built, e.Path)
+	})
+	t.Logf("Build() => %v", err)
+
+	var abortErr static.AbortError
+	if !errors.As(err, &abortErr) {
+		t.Fatalf("Build() => %#v, expected an AbortError", err)
+	}
+	if abortErr.Path != paths[0] || abortErr.Err == nil {
+		t.Errorf("AbortError => %#v, expected Path %s and an Err", abortErr, paths[0])
+	}
+	if len(built) == len(paths) || len(built)+len(abortErr.Unbuilt) != len(paths) {
+		t.Errorf("Paths built => %v, Unbuilt => %v, expected some paths unbuilt and all paths accounted for", built, abortErr.Unbuilt)
+	}
+	for i, path := range abortErr.Unbuilt {
+		if expected := paths[len(built)+i]; path != expected {
+			t.Errorf("Unbuilt[%d] => %s, expected %s", i, path, expected)
+		}
+	}
+}
+
+func TestBuildMaxErrorsNotReached(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /* and response with Hello <path>!")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/hello/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+	})
+
+	t.Log("And Options are defined with defaults, a MaxErrors of 1, a MaxErrorPercent of 10 and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	options.MaxErrors = 1
+	options.MaxErrorPercent = 10
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And there are multiple paths to build.")
+	paths := []string{
+		"/hello/go",
+		"/hello/world",
+	}
+
+	t.Log("Expect Build to build all paths and return nil.")
+	err := static.Build(options, handler, paths, nil)
+	if err != nil {
+		t.Errorf("Build() => %v, expected nil", err)
+	}
+}
+
+func TestBuildMaxErrorPercentLocales(t *testing.T) {
+	t.Log("When a Handler is defined to respond to any path with Hello!")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello!")
+	})
+
+	t.Log("And there are 10 paths to build.")
+	var paths []string
+	for i := 0; i < 10; i++ {
+		paths = append(paths, fmt.Sprintf("/page%d", i))
+	}
+
+	tests := []struct {
+		failingLocales []string
+		failingPaths   int
+		expectAbort    bool
+	}{
+		{[]string{"de", "fr"}, 4, false},
+		{[]string{"fr"}, 10, true},
+	}
+
+	for _, test := range tests {
+		t.Logf("And Options are defined with defaults, the Locales en, de and fr prefixed to paths, a MaxErrorPercent of 50, and a PrepareRequest that fails the first %d paths in %v.", test.failingPaths, test.failingLocales)
+		options := static.DefaultOptions
+		tempDir, _ := ioutil.TempDir("", "")
+		options.OutputDir = filepath.Join(tempDir, "build")
+		options.MaxErrorPercent = 50
+		options.Locales = &static.Locales{Locales: []string{"en", "de", "fr"}, Default: "en", Via: static.LocaleViaPrefix}
+		failing := map[string]bool{}
+		for _, locale := range test.failingLocales {
+			for _, path := range paths[:test.failingPaths] {
+				failing["/"+locale+path] = true
+			}
+		}
+		options.PrepareRequest = func(r *http.Request) error {
+			if failing[r.URL.Path] {
+				return errors.New("failed")
+			}
+			return nil
+		}
+
+		t.Logf("Expect Build to count each path once however many locales it fails in, and abort %v.", test.expectAbort)
+		err := static.Build(options, handler, paths, nil)
+		t.Logf("Build() => %v", err)
+		var abortErr static.AbortError
+		if errors.As(err, &abortErr) != test.expectAbort {
+			t.Errorf("Build() => %v, expected abort %v", err, test.expectAbort)
+		}
+	}
+}
@@ -16,6 +16,20 @@ type Event struct {
 	OutputPath string
 	// An error if an error occurred while performing the action, otherwise nil.
 	Error error
+	// The number of paths being built concurrently, for concurrency events.
+	Concurrency int
+	// The host the path was built for, if the build has sites.
+	Host string
+	// The locale the path was built in, if the build has Locales.
+	Locale string
+	// The Accept header the path was requested with, if the path has Variants.
+	Variant string
+	// The ways the handler used the http.ResponseWriter that net/http would ignore, such as superfluous WriteHeader calls and informational 1xx responses, separated by semicolons.
+	Warning string
+	// The name of the PostProcessor, for postprocess events.
+	PostProcessor string
+	// The number of bytes removed from the body of the path by post-processing, negative if post-processing added bytes. For postprocess events it is the bytes saved by the PostProcessor, and for build events by all PostProcessors.
+	BytesSaved int64
 }
 
 // Action is something taken place, captured in an Event.
@@ -24,14 +38,71 @@ type Action string
 const (
 	// BUILD is the building of a path.
 	BUILD Action = "build"
+	// RETRY is a failed attempt at building a path that will be attempted again.
+	RETRY Action = "retry"
+	// CONCURRENCY is a change in the number of paths built concurrently.
+	CONCURRENCY Action = "concurrency"
+	// COPY is the copying of a static asset file.
+	COPY Action = "copy"
+	// SKIP is the skipping of a file whose output is already up to date.
+	SKIP Action = "skip"
+	// SITEMAP is the writing of a generated sitemap.
+	SITEMAP Action = "sitemap"
+	// MANIFEST is the writing of a generated manifest.
+	MANIFEST Action = "manifest"
+	// DUPLICATE is the skipping of a path that is the same as a path already being built.
+	DUPLICATE Action = "duplicate"
+	// MISSING is a file in a manifest that is not in the OutputDir.
+	MISSING Action = "missing"
+	// EXTRA is a file in the OutputDir that is not in a manifest.
+	EXTRA Action = "extra"
+	// MODIFIED is a file in the OutputDir that is different to the file in a manifest.
+	MODIFIED Action = "modified"
+	// POSTPROCESS is the transforming of the body of a path by a PostProcessor.
+	POSTPROCESS Action = "postprocess"
 )
 
 // A simple string representation of an Event in the format:
 //	 Action: build, Path: <path>, StatusCode: 200|404|etc, OutputPath: <output-path>
+// And when the Event has a concurrency:
+//	 Action: concurrency, Path: , StatusCode: 0, OutputPath: , Concurrency: <concurrency>
+// And when the Event has a host:
+//	 Action: build, Path: <path>, StatusCode: 200|404|etc, OutputPath: <output-path>, Host: <host>
+// And when the Event has a locale:
+//	 Action: build, Path: <path>, StatusCode: 200|404|etc, OutputPath: <output-path>, Locale: <locale>
+// And when the Event has a variant:
+//	 Action: build, Path: <path>, StatusCode: 200|404|etc, OutputPath: <output-path>, Variant: <accept>
+// And when the Event has a warning:
+//	 Action: build, Path: <path>, StatusCode: 200|404|etc, OutputPath: <output-path>, Warning: <warning>
+// And when the Event has a post processor:
+//	 Action: postprocess, Path: <path>, StatusCode: 0, OutputPath: <output-path>, PostProcessor: <name>, BytesSaved: <bytes-saved>
+// And when the Event has bytes saved:
+//	 Action: build, Path: <path>, StatusCode: 200|404|etc, OutputPath: <output-path>, BytesSaved: <bytes-saved>
 // And when the Event has an error:
 //	 Action: build, Path: <path>, StatusCode: 200|404|etc, OutputPath: <output-path>, Error: <error>
 func (e Event) String() string {
 	s := fmt.Sprintf("Action: %s, Path: %s, StatusCode: %d, OutputPath: %s", e.Action, e.Path, e.StatusCode, e.OutputPath)
+	if e.Concurrency != 0 {
+		s += fmt.Sprintf(", Concurrency: %d", e.Concurrency)
+	}
+	if e.Host != "" {
+		s += fmt.Sprintf(", Host: %s", e.Host)
+	}
+	if e.Locale != "" {
+		s += fmt.Sprintf(", Locale: %s", e.Locale)
+	}
+	if e.Variant != "" {
+		s += fmt.Sprintf(", Variant: %s", e.Variant)
+	}
+	if e.Warning != "" {
+		s += fmt.Sprintf(", Warning: %s", e.Warning)
+	}
+	if e.PostProcessor != "" {
+		s += fmt.Sprintf(", PostProcessor: %s", e.PostProcessor)
+	}
+	if e.BytesSaved != 0 {
+		s += fmt.Sprintf(", BytesSaved: %d", e.BytesSaved)
+	}
 	if e.Error != nil {
 		s += fmt.Sprintf(", Error: %v", e.Error)
 	}
@@ -15,6 +15,13 @@ func TestString(t *testing.T) {
 		{static.Event{Action: "action", Path: "/path", StatusCode: 200, OutputPath: "/output-path/path"}, "Action: action, Path: /path, StatusCode: 200, OutputPath: /output-path/path"},
 		{static.Event{Action: "action", Path: "/path", StatusCode: 404, OutputPath: "/output-path/path"}, "Action: action, Path: /path, StatusCode: 404, OutputPath: /output-path/path"},
 		{static.Event{Action: "action", Path: "/path", StatusCode: 200, OutputPath: "/output-path/path", Error: errors.New("error")}, "Action: action, Path: /path, StatusCode: 200, OutputPath: /output-path/path, Error: error"},
+		{static.Event{Action: "concurrency", Concurrency: 5}, "Action: concurrency, Path: , StatusCode: 0, OutputPath: , Concurrency: 5"},
+		{static.Event{Action: "action", Path: "/path", StatusCode: 200, OutputPath: "/output-path/fr/path", Locale: "fr"}, "Action: action, Path: /path, StatusCode: 200, OutputPath: /output-path/fr/path, Locale: fr"},
+		{static.Event{Action: "action", Path: "/path", StatusCode: 200, OutputPath: "/output-path/path.json", Variant: "application/json"}, "Action: action, Path: /path, StatusCode: 200, OutputPath: /output-path/path.json, Variant: application/json"},
+		{static.Event{Action: "action", Path: "/path", StatusCode: 200, OutputPath: "/output-path/path", Warning: "superfluous WriteHeader call with status 500"}, "Action: action, Path: /path, StatusCode: 200, OutputPath: /output-path/path, Warning: superfluous WriteHeader call with status 500"},
+		{static.Event{Action: "postprocess", Path: "/path", OutputPath: "/output-path/path", PostProcessor: "html", BytesSaved: 42}, "Action: postprocess, Path: /path, StatusCode: 0, OutputPath: /output-path/path, PostProcessor: html, BytesSaved: 42"},
+		{static.Event{Action: "action", Path: "/path", StatusCode: 200, OutputPath: "/output-path/path", BytesSaved: -3}, "Action: action, Path: /path, StatusCode: 200, OutputPath: /output-path/path, BytesSaved: -3"},
+		{static.Event{Action: "action", Path: "/path", StatusCode: 200, OutputPath: "/output-path/example.com/path", Host: "example.com"}, "Action: action, Path: /path, StatusCode: 200, OutputPath: /output-path/example.com/path, Host: example.com"},
 	}
 
 	for _, test := range tests {
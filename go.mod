@@ -0,0 +1,3 @@
+module 4d63.com/static
+
+go 1.23
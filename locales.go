@@ -0,0 +1,229 @@
+package static
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LocaleVia is how the locale being built reaches the request made to the handler.
+type LocaleVia int
+
+const (
+	// LocaleViaHeader sets the Accept-Language header of the request to the locale.
+	LocaleViaHeader LocaleVia = iota
+	// LocaleViaQuery sets a query parameter of the request to the locale.
+	LocaleViaQuery
+	// LocaleViaPrefix prefixes the path of the request with the locale. e.g. /fr/about
+	LocaleViaPrefix
+)
+
+// Locales for configuring Build to build every path once for each locale. Each locale other than the Default is written to a directory in the OutputDir named after the locale, and served under a path prefix of the same name. e.g. /about in the locale fr is written to fr/about.
+type Locales struct {
+	// The locales to build. e.g. en, fr, de
+	Locales []string
+	// The locale written to the root of the OutputDir and served without a prefix. Empty means every locale is written to its own directory.
+	Default string
+	// How the locale reaches the request made to the handler.
+	Via LocaleVia
+	// The name of the query parameter when Via is LocaleViaQuery. Empty means lang.
+	QueryParam string
+	// The path of a sitemap written after building that lists every path built successfully in each locale, with its alternates in the locales it was built successfully in. e.g. /sitemap.xml. Empty means no sitemap is written.
+	Sitemap string
+	// The origin of the locations in the Sitemap. e.g. https://example.com. Required if there is a Sitemap, because a sitemap must list absolute locations.
+	Origin string
+}
+
+// Alternate is the path a page is served at in a locale, for linking to it from the page in other locales with hreflang.
+type Alternate struct {
+	// The locale of the page, or x-default for the page in the Default locale.
+	Locale string
+	// The path the page is served at in the locale.
+	Path string
+}
+
+// Path returns the path the page at the path is served at in the locale.
+func (l *Locales) Path(locale, path string) string {
+	if locale == l.Default {
+		return path
+	}
+	return "/" + locale + path
+}
+
+// Alternates returns the alternates of the page at the path in every locale, including x-default if there is a Default locale, for rendering as <link rel="alternate" hreflang="..." href="..."> elements.
+func (l *Locales) Alternates(path string) []Alternate {
+	alternates := make([]Alternate, 0, len(l.Locales)+1)
+	for _, locale := range l.Locales {
+		alternates = append(alternates, Alternate{Locale: locale, Path: l.Path(locale, path)})
+	}
+	if l.Default != "" {
+		alternates = append(alternates, Alternate{Locale: "x-default", Path: l.Path(l.Default, path)})
+	}
+	return alternates
+}
+
+// target returns the target for building the path of the base target in the locale.
+func (l *Locales) target(base buildTarget, locale string) buildTarget {
+	t := base
+	t.locale = locale
+	o := base.o
+
+	switch l.Via {
+	case LocaleViaHeader:
+		request := RequestTemplate{}
+		if o.Request != nil {
+			request = *o.Request
+		}
+		request.Header = request.Header.Clone()
+		if request.Header == nil {
+			request.Header = http.Header{}
+		}
+		request.Header.Set("Accept-Language", locale)
+		t.o.Request = &request
+	case LocaleViaQuery:
+		param := l.QueryParam
+		if param == "" {
+			param = "lang"
+		}
+		prepare := o.PrepareRequest
+		t.o.PrepareRequest = func(r *http.Request) error {
+			q := r.URL.Query()
+			q.Set(param, locale)
+			r.URL.RawQuery = q.Encode()
+			if prepare != nil {
+				return prepare(r)
+			}
+			return nil
+		}
+	case LocaleViaPrefix:
+		t.path = l.Path(locale, base.path)
+		return t
+	}
+
+	if locale != l.Default {
+		t.o.OutputDir = filepath.Join(o.OutputDir, locale)
+	}
+	return t
+}
+
+// localeSitemap collects the locales each path was built successfully in, for writing a sitemap with their alternates.
+type localeSitemap struct {
+	mutex sync.Mutex
+	paths map[string]map[string]bool
+}
+
+func (s *localeSitemap) record(path, locale string, statusCode int, err error) {
+	if s == nil || err != nil || !built(statusCode) {
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.paths[path] == nil {
+		s.paths[path] = map[string]bool{}
+	}
+	s.paths[path][locale] = true
+}
+
+// write the sitemap for the Locales into the OutputDir, calling the EventHandler with the result.
+func (s *localeSitemap) write(o Options, eh EventHandler) {
+	if s == nil {
+		return
+	}
+
+	l := o.Locales
+	paths := make([]string, 0, len(s.paths))
+	for path := range s.paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	outputPath := filepath.Join(o.OutputDir, filepath.FromSlash(l.Sitemap))
+	f, err := createFile(o, outputPath, l.Sitemap)
+	if err != nil {
+		eh(Event{Action: SITEMAP, Path: l.Sitemap, Error: err})
+		return
+	}
+	defer f.Close()
+
+	err = l.writeSitemap(f, paths, s.paths)
+	if err != nil {
+		message := fmt.Sprintf("Unable to write sitemap %s", outputPath)
+		eh(Event{Action: SITEMAP, Path: l.Sitemap, Error: buildError{message, err}})
+		return
+	}
+	err = setModTime(o, outputPath, l.Sitemap, nil)
+	eh(Event{Action: SITEMAP, Path: l.Sitemap, OutputPath: outputPath, Error: err})
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	XHTML   string       `xml:"xmlns:xhtml,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc   string        `xml:"loc"`
+	Links []sitemapLink `xml:"xhtml:link"`
+}
+
+type sitemapLink struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// writeSitemap writes a sitemap listing every path in each locale it was built in, each with links to its alternates in those locales.
+func (l *Locales) writeSitemap(w io.Writer, paths []string, built map[string]map[string]bool) error {
+	origin := strings.TrimSuffix(l.Origin, "/")
+	// Only the path is escaped, so that the query of a path is kept as is.
+	loc := func(path string) string {
+		path, query, hasQuery := strings.Cut(path, "?")
+		u := url.URL{Path: path}
+		if hasQuery {
+			return origin + u.EscapedPath() + "?" + query
+		}
+		return origin + u.EscapedPath()
+	}
+
+	set := sitemapURLSet{
+		XMLNS: "http://www.sitemaps.org/schemas/sitemap/0.9",
+		XHTML: "http://www.w3.org/1999/xhtml",
+	}
+	for _, path := range paths {
+		var links []sitemapLink
+		for _, a := range l.Alternates(path) {
+			locale := a.Locale
+			if locale == "x-default" {
+				locale = l.Default
+			}
+			if built[path][locale] {
+				links = append(links, sitemapLink{Rel: "alternate", Hreflang: a.Locale, Href: loc(a.Path)})
+			}
+		}
+		for _, locale := range l.Locales {
+			if built[path][locale] {
+				set.URLs = append(set.URLs, sitemapURL{Loc: loc(l.Path(locale, path)), Links: links})
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	err = e.Encode(set)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
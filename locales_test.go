@@ -0,0 +1,178 @@
+package static_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"4d63.com/static"
+)
+
+func TestBuildLocales(t *testing.T) {
+	t.Log("When a Handler is defined to respond with the path and the locale from the Accept-Language header, lang query parameter, or path prefix.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s header=%s query=%s", r.URL.Path, r.Header.Get("Accept-Language"), r.URL.Query().Get("lang"))
+	})
+
+	tests := []struct {
+		via              static.LocaleVia
+		expectedPaths    map[string]string
+		expectedContents map[string]string
+	}{
+		{
+			static.LocaleViaHeader,
+			map[string]string{"en": "/about", "fr": "/about"},
+			map[string]string{"about": "/about header=en query=", "fr/about": "/about header=fr query="},
+		},
+		{
+			static.LocaleViaQuery,
+			map[string]string{"en": "/about", "fr": "/about"},
+			map[string]string{"about": "/about header= query=en", "fr/about": "/about header= query=fr"},
+		},
+		{
+			static.LocaleViaPrefix,
+			map[string]string{"en": "/about", "fr": "/fr/about"},
+			map[string]string{"about": "/about header= query=", "fr/about": "/fr/about header= query="},
+		},
+	}
+
+	for _, test := range tests {
+		t.Logf("And Options are defined with defaults, an OutputDir that does not exist, and the Locales en and fr with en the Default, via %d.", test.via)
+		options := static.DefaultOptions
+		tempDir, _ := ioutil.TempDir("", "")
+		options.OutputDir = filepath.Join(tempDir, "build")
+		options.Locales = &static.Locales{Locales: []string{"en", "fr"}, Default: "en", Via: test.via}
+
+		t.Log("Expect Build to build /about once for each locale, writing the Default locale to the root of the OutputDir and fr to the fr directory.")
+		var mutex sync.Mutex
+		paths := map[string]string{}
+		static.Build(options, handler, []string{"/about"}, func(e static.Event) {
+			t.Logf("Event received => %v", e)
+			mutex.Lock()
+			defer mutex.Unlock()
+			paths[e.Locale] = e.Path
+		})
+		if !reflect.DeepEqual(paths, test.expectedPaths) {
+			t.Errorf("Paths built => %v, expected %v", paths, test.expectedPaths)
+		}
+		for file, expectedContents := range test.expectedContents {
+			outputFilePath := filepath.Join(options.OutputDir, filepath.FromSlash(file))
+			contents, err := ioutil.ReadFile(outputFilePath)
+			if err != nil || string(contents) != expectedContents {
+				t.Errorf("Contents of %s => %s, %v, expected %s", outputFilePath, contents, err, expectedContents)
+			}
+		}
+	}
+}
+
+func TestBuildLocalesSitemap(t *testing.T) {
+	t.Log("When a Handler is defined to respond to / and /about, and with 404 Not Found to /about in fr and to other paths.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" && r.URL.Path != "/about" || r.URL.Path == "/about" && r.Header.Get("Accept-Language") == "fr" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "Hello!")
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and the Locales en and fr with en the Default and a Sitemap.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Locales = &static.Locales{Locales: []string{"en", "fr"}, Default: "en", Sitemap: "/sitemap.xml", Origin: "https://example.com"}
+
+	t.Log("Expect Build to write a sitemap listing the pages that were found in each locale with their alternates that were found.")
+	sitemapEvents := 0
+	static.Build(options, handler, []string{"/about", "/", "/missing"}, func(e static.Event) {
+		if e.Action == static.SITEMAP {
+			t.Logf("Event received => %v", e)
+			sitemapEvents++
+		}
+	})
+	if sitemapEvents != 1 {
+		t.Errorf("Sitemap events received => %d, expected 1", sitemapEvents)
+	}
+
+	links := `
+    <xhtml:link rel="alternate" hreflang="en" href="https://example.com%[1]s"></xhtml:link>
+    <xhtml:link rel="alternate" hreflang="fr" href="https://example.com/fr%[1]s"></xhtml:link>
+    <xhtml:link rel="alternate" hreflang="x-default" href="https://example.com%[1]s"></xhtml:link>`
+	expectedSitemap := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:xhtml="http://www.w3.org/1999/xhtml">
+  <url>
+    <loc>https://example.com/</loc>` + fmt.Sprintf(links, "/") + `
+  </url>
+  <url>
+    <loc>https://example.com/fr/</loc>` + fmt.Sprintf(links, "/") + `
+  </url>
+  <url>
+    <loc>https://example.com/about</loc>
+    <xhtml:link rel="alternate" hreflang="en" href="https://example.com/about"></xhtml:link>
+    <xhtml:link rel="alternate" hreflang="x-default" href="https://example.com/about"></xhtml:link>
+  </url>
+</urlset>
+`
+	sitemapPath := filepath.Join(options.OutputDir, "sitemap.xml")
+	sitemap, err := ioutil.ReadFile(sitemapPath)
+	if err != nil || string(sitemap) != expectedSitemap {
+		t.Errorf("Contents of %s => %s, %v, expected %s", sitemapPath, sitemap, err, expectedSitemap)
+	}
+}
+
+func TestBuildLocalesSitemapQuery(t *testing.T) {
+	t.Log("When a Handler is defined to respond to every path.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello!")
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and the Locales en and fr with en the Default and a Sitemap without an Origin.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Locales = &static.Locales{Locales: []string{"en", "fr"}, Default: "en", Sitemap: "/sitemap.xml"}
+
+	t.Log("Expect Build to return an error without building any paths.")
+	built := 0
+	err := static.Build(options, handler, []string{"/search?page=2"}, func(e static.Event) {
+		built++
+	})
+	if err == nil || built != 0 {
+		t.Errorf("Build() => %v, %d events, expected an error and no events", err, built)
+	}
+
+	t.Log("And when the Locales have an Origin.")
+	options.Locales.Origin = "https://example.com/"
+
+	t.Log("Expect Build to write a sitemap with the path escaped and the query kept as is.")
+	err = static.Build(options, handler, []string{"/a b?page=2&q=x%20y"}, nil)
+	if err != nil {
+		t.Fatalf("Build() => %v, expected nil", err)
+	}
+	sitemapPath := filepath.Join(options.OutputDir, "sitemap.xml")
+	sitemap, err := ioutil.ReadFile(sitemapPath)
+	expected := "<loc>https://example.com/a%20b?page=2&amp;q=x%20y</loc>"
+	if err != nil || !strings.Contains(string(sitemap), expected) {
+		t.Errorf("Contents of %s => %s, %v, expected to contain %s", sitemapPath, sitemap, err, expected)
+	}
+}
+
+func TestLocalesAlternates(t *testing.T) {
+	locales := static.Locales{Locales: []string{"en", "fr"}, Default: "en"}
+	expected := []static.Alternate{{"en", "/about"}, {"fr", "/fr/about"}, {"x-default", "/about"}}
+	alternates := locales.Alternates("/about")
+	if !reflect.DeepEqual(alternates, expected) {
+		t.Errorf("Alternates(/about) => %v, expected %v", alternates, expected)
+	}
+
+	locales.Default = ""
+	expected = []static.Alternate{{"en", "/en/about"}, {"fr", "/fr/about"}}
+	alternates = locales.Alternates("/about")
+	if !reflect.DeepEqual(alternates, expected) {
+		t.Errorf("Alternates(/about) => %v, expected %v", alternates, expected)
+	}
+}
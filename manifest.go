@@ -0,0 +1,289 @@
+package static
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Manifest is the JSON manifest written to the Manifest path of the Options, listing every file in the OutputDir, sorted by file and then path so that two builds of the same content write the same manifest.
+type Manifest struct {
+	Files []ManifestFile `json:"files"`
+}
+
+// ManifestFile is a file in a Manifest, and the path it was built from. Files that were not built from a path, such as assets and sitemaps, have no path.
+type ManifestFile struct {
+	// The path the file was built from.
+	Path string `json:"path,omitempty"`
+	// The host, locale and Accept header the path was built for, if the build has sites, Locales or Variants.
+	Host    string `json:"host,omitempty"`
+	Locale  string `json:"locale,omitempty"`
+	Variant string `json:"variant,omitempty"`
+	// The file relative to the OutputDir, with forward slashes.
+	File string `json:"file"`
+	// The HTTP status code and Content-Type returned by the handler when the path was built.
+	StatusCode  int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	// The size of the file in bytes, and the SHA-256 of its contents in hex.
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// buildManifest collects the paths built successfully, for writing a Manifest.
+type buildManifest struct {
+	mutex     sync.Mutex
+	outputDir string
+	files     []ManifestFile
+	// The output path of each file, for hashing the files when the Manifest is written.
+	outputPaths []string
+	// The files in the Manifest written by the previous build, for the status and Content-Type of files kept as is by Conditional builds.
+	previous map[manifestKey]ManifestFile
+}
+
+// manifestKey identifies a file built from a path in a Manifest.
+type manifestKey struct {
+	file, path, host, locale, variant string
+}
+
+// newBuildManifest returns a buildManifest for the Options, with the files of the Manifest in the OutputDir from a previous build, if there is one.
+func newBuildManifest(o Options) *buildManifest {
+	m := &buildManifest{outputDir: o.OutputDir, previous: map[manifestKey]ManifestFile{}}
+	previous, err := readManifest(o)
+	if err != nil {
+		return m
+	}
+	for _, f := range previous.Files {
+		m.previous[manifestKey{f.File, f.Path, f.Host, f.Locale, f.Variant}] = f
+	}
+	return m
+}
+
+func (m *buildManifest) record(t buildTarget, r buildResult, err error) {
+	if m == nil || err != nil || r.outputPath == "" {
+		return
+	}
+	f := ManifestFile{
+		Path:        t.path,
+		Host:        t.host,
+		Locale:      t.locale,
+		Variant:     t.variant.Accept,
+		StatusCode:  r.statusCode,
+		ContentType: r.contentType,
+	}
+	if r.statusCode == http.StatusNotModified {
+		// The file was kept as is, so it is listed as it was when it was written, so that the Manifest is the same as after a clean build.
+		f.StatusCode, f.ContentType = http.StatusOK, ""
+		if file, err := filepath.Rel(m.outputDir, r.outputPath); err == nil {
+			if previous, ok := m.previous[manifestKey{filepath.ToSlash(file), f.Path, f.Host, f.Locale, f.Variant}]; ok {
+				f.StatusCode, f.ContentType = previous.StatusCode, previous.ContentType
+			}
+		}
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.files = append(m.files, f)
+	m.outputPaths = append(m.outputPaths, r.outputPath)
+}
+
+// manifest returns the Manifest of the recorded paths and every other file in the OutputDir, except the manifest itself.
+func (m *buildManifest) manifest(o Options) (Manifest, error) {
+	manifestPath := filepath.Join(o.OutputDir, filepath.FromSlash(o.Manifest))
+	files := map[string]ManifestFile{}
+	err := filepath.WalkDir(o.OutputDir, func(outputPath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || outputPath == manifestPath {
+			return err
+		}
+		f, err := hashFile(o, outputPath)
+		if err != nil {
+			return err
+		}
+		files[outputPath] = f
+		return nil
+	})
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	manifest := Manifest{Files: []ManifestFile{}}
+	built := map[string]bool{}
+	for i, f := range m.files {
+		outputPath := m.outputPaths[i]
+		hashed, ok := files[outputPath]
+		if !ok {
+			continue
+		}
+		f.File, f.Size, f.SHA256 = hashed.File, hashed.Size, hashed.SHA256
+		manifest.Files = append(manifest.Files, f)
+		built[outputPath] = true
+	}
+	for outputPath, f := range files {
+		if !built[outputPath] {
+			manifest.Files = append(manifest.Files, f)
+		}
+	}
+
+	sort.Slice(manifest.Files, func(i, j int) bool {
+		a, b := manifest.Files[i], manifest.Files[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		if a.Locale != b.Locale {
+			return a.Locale < b.Locale
+		}
+		return a.Variant < b.Variant
+	})
+	return manifest, nil
+}
+
+// write the Manifest into the OutputDir, calling the EventHandler with the result.
+func (m *buildManifest) write(o Options, eh EventHandler) {
+	if m == nil {
+		return
+	}
+
+	outputPath := filepath.Join(o.OutputDir, filepath.FromSlash(o.Manifest))
+	manifest, err := m.manifest(o)
+	if err != nil {
+		message := fmt.Sprintf("Unable to hash files for manifest %s", outputPath)
+		eh(Event{Action: MANIFEST, Path: o.Manifest, Error: buildError{message, err}})
+		return
+	}
+
+	f, err := createFile(o, outputPath, o.Manifest)
+	if err != nil {
+		eh(Event{Action: MANIFEST, Path: o.Manifest, Error: err})
+		return
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(manifest)
+	if err != nil {
+		message := fmt.Sprintf("Unable to write manifest %s", outputPath)
+		eh(Event{Action: MANIFEST, Path: o.Manifest, Error: buildError{message, err}})
+		return
+	}
+	err = setModTime(o, outputPath, o.Manifest, nil)
+	eh(Event{Action: MANIFEST, Path: o.Manifest, OutputPath: outputPath, Error: err})
+}
+
+// hashFile returns a ManifestFile with the file relative to the OutputDir, size and SHA-256 of the output file.
+func hashFile(o Options, outputPath string) (ManifestFile, error) {
+	f, err := os.Open(outputPath)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	file, err := filepath.Rel(o.OutputDir, outputPath)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	return ManifestFile{File: filepath.ToSlash(file), Size: size, SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// readManifest reads the Manifest written to the Manifest path of the Options.
+func readManifest(o Options) (Manifest, error) {
+	manifestPath := filepath.Join(o.OutputDir, filepath.FromSlash(o.Manifest))
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		message := fmt.Sprintf("Unable to read manifest %s", manifestPath)
+		return Manifest{}, buildError{message, err}
+	}
+	var manifest Manifest
+	err = json.Unmarshal(data, &manifest)
+	if err != nil {
+		message := fmt.Sprintf("Unable to read manifest %s", manifestPath)
+		return Manifest{}, buildError{message, err}
+	}
+	return manifest, nil
+}
+
+// Verify checks the files in the OutputDir against the Manifest written to the Manifest path of the Options by Build, and calls the EventHandler for every file that is missing from the OutputDir, in the OutputDir but not in the Manifest, or modified since the Manifest was written. EventHandler may be nil. Returns an error if the Manifest cannot be read, or if any files are missing, extra or modified.
+func Verify(o Options, eh EventHandler) error {
+	if eh == nil {
+		eh = defaultEventHandler
+	}
+
+	manifestPath := filepath.Join(o.OutputDir, filepath.FromSlash(o.Manifest))
+	manifest, err := readManifest(o)
+	if err != nil {
+		return err
+	}
+
+	expected := map[string]ManifestFile{}
+	var files []string
+	for _, f := range manifest.Files {
+		if _, ok := expected[f.File]; !ok {
+			files = append(files, f.File)
+		}
+		expected[f.File] = f
+	}
+
+	missing, extra, modified := 0, 0, 0
+	for _, file := range files {
+		f := expected[file]
+		outputPath := filepath.Join(o.OutputDir, filepath.FromSlash(file))
+		actual, err := hashFile(o, outputPath)
+		switch {
+		case os.IsNotExist(err):
+			missing++
+			message := fmt.Sprintf("File %s in manifest is missing", file)
+			eh(Event{Action: MISSING, Path: f.Path, OutputPath: outputPath, Error: buildError{message, nil}})
+		case err != nil:
+			modified++
+			message := fmt.Sprintf("Unable to read file %s in manifest", file)
+			eh(Event{Action: MODIFIED, Path: f.Path, OutputPath: outputPath, Error: buildError{message, err}})
+		case actual.Size != f.Size || actual.SHA256 != f.SHA256:
+			modified++
+			message := fmt.Sprintf("File %s has size %d and SHA-256 %s, expected %d and %s", file, actual.Size, actual.SHA256, f.Size, f.SHA256)
+			eh(Event{Action: MODIFIED, Path: f.Path, OutputPath: outputPath, Error: buildError{message, nil}})
+		}
+	}
+
+	err = filepath.WalkDir(o.OutputDir, func(outputPath string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || outputPath == manifestPath {
+			return err
+		}
+		file, err := filepath.Rel(o.OutputDir, outputPath)
+		if err != nil {
+			return err
+		}
+		if _, ok := expected[filepath.ToSlash(file)]; !ok {
+			extra++
+			message := fmt.Sprintf("File %s is not in manifest", filepath.ToSlash(file))
+			eh(Event{Action: EXTRA, OutputPath: outputPath, Error: buildError{message, nil}})
+		}
+		return nil
+	})
+	if err != nil {
+		message := fmt.Sprintf("Unable to list files in %s", o.OutputDir)
+		return buildError{message, err}
+	}
+
+	if missing+extra+modified > 0 {
+		message := fmt.Sprintf("Verification against manifest %s found %d missing, %d extra and %d modified files", manifestPath, missing, extra, modified)
+		return buildError{message, nil}
+	}
+	return nil
+}
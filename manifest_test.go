@@ -0,0 +1,168 @@
+package static_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"4d63.com/static"
+)
+
+func TestBuildManifest(t *testing.T) {
+	t.Log("When a Handler is defined to respond with Hello <path>!, and with 404 Not Found to /missing.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("Hello " + r.URL.Path + "!"))
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and a Manifest.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Manifest = "/manifest.json"
+
+	t.Log("Expect Build to write a Manifest listing every file with its path, status, Content-Type, size and SHA-256, sorted by file.")
+	err := static.Build(options, handler, []string{"/b", "/a/", "/missing"}, nil)
+	if err != nil {
+		t.Fatalf("Build() => %v, expected nil", err)
+	}
+	contents, err := ioutil.ReadFile(filepath.Join(options.OutputDir, "manifest.json"))
+	if err != nil {
+		t.Fatalf("Error reading manifest => %v", err)
+	}
+	var manifest static.Manifest
+	err = json.Unmarshal(contents, &manifest)
+	if err != nil {
+		t.Fatalf("Error decoding manifest => %v", err)
+	}
+	hash := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:])
+	}
+	notFound := "404 page not found\n"
+	expectedManifest := static.Manifest{Files: []static.ManifestFile{
+		{Path: "/a/", File: "a/index.html", StatusCode: 200, ContentType: "text/plain", Size: 10, SHA256: hash("Hello /a/!")},
+		{Path: "/b", File: "b", StatusCode: 200, ContentType: "text/plain", Size: 9, SHA256: hash("Hello /b!")},
+		{Path: "/missing", File: "missing", StatusCode: 404, ContentType: "text/plain; charset=utf-8", Size: int64(len(notFound)), SHA256: hash(notFound)},
+	}}
+	if !reflect.DeepEqual(manifest, expectedManifest) {
+		t.Errorf("Manifest => %+v, expected %+v", manifest, expectedManifest)
+	}
+
+	t.Log("And when the same paths are built again into another OutputDir, in a different order.")
+	options2 := options
+	options2.OutputDir = filepath.Join(tempDir, "build2")
+	static.Build(options2, handler, []string{"/missing", "/a/", "/b"}, nil)
+
+	t.Log("Expect the Manifest to be identical.")
+	contents2, err := ioutil.ReadFile(filepath.Join(options2.OutputDir, "manifest.json"))
+	if err != nil || string(contents2) != string(contents) {
+		t.Errorf("Manifest => %s, %v, expected %s", contents2, err, contents)
+	}
+}
+
+func TestBuildManifestConditional(t *testing.T) {
+	t.Log("When a Handler is defined to respond with http.ServeContent with a fixed modification time.")
+	modTime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "page.html", modTime, strings.NewReader("Hello "+r.URL.Path+"!"))
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, Conditional, and a Manifest.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Conditional = &static.Conditional{}
+	options.Manifest = "/manifest.json"
+	manifestPath := filepath.Join(options.OutputDir, "manifest.json")
+
+	t.Log("Expect a clean Build to write a Manifest with the status and Content-Type of each path.")
+	err := static.Build(options, handler, []string{"/a", "/b"}, nil)
+	if err != nil {
+		t.Fatalf("Build() => %v, expected nil", err)
+	}
+	clean, err := ioutil.ReadFile(manifestPath)
+	if err != nil || !strings.Contains(string(clean), `"status": 200`) || !strings.Contains(string(clean), `"contentType": "text/html; charset=utf-8"`) {
+		t.Errorf("Manifest => %s, %v, expected status 200 and a Content-Type", clean, err)
+	}
+
+	t.Log("And when the paths are built again, and the handler responds 304 Not Modified so the files are kept.")
+	statusCodes := map[string]int{}
+	var mutex sync.Mutex
+	err = static.Build(options, handler, []string{"/a", "/b"}, func(e static.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if e.Action == static.BUILD {
+			statusCodes[e.Path] = e.StatusCode
+		}
+	})
+	if err != nil || statusCodes["/a"] != 304 || statusCodes["/b"] != 304 {
+		t.Fatalf("Build() => %v, %v, expected nil and 304 for both paths", err, statusCodes)
+	}
+
+	t.Log("Expect the Manifest to be identical to the Manifest of the clean build.")
+	incremental, err := ioutil.ReadFile(manifestPath)
+	if err != nil || string(incremental) != string(clean) {
+		t.Errorf("Manifest => %s, %v, expected %s", incremental, err, clean)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	t.Log("When a Handler is defined to respond with Hello <path>!")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello " + r.URL.Path + "!"))
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and a Manifest.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Manifest = "/manifest.json"
+
+	t.Log("And the paths /a, /b and /c are built.")
+	static.Build(options, handler, []string{"/a", "/b", "/c"}, nil)
+
+	t.Log("Expect Verify to return nil and call the EventHandler with nothing.")
+	err := static.Verify(options, func(e static.Event) {
+		t.Errorf("Event received => %v, expected no events", e)
+	})
+	if err != nil {
+		t.Errorf("Verify() => %v, expected nil", err)
+	}
+
+	t.Log("And when /a is removed, /b is modified, and /d is added.")
+	os.Remove(filepath.Join(options.OutputDir, "a"))
+	ioutil.WriteFile(filepath.Join(options.OutputDir, "b"), []byte("Goodbye!"), 0644)
+	ioutil.WriteFile(filepath.Join(options.OutputDir, "d"), []byte("Hello /d!"), 0644)
+
+	t.Log("Expect Verify to return an error and call the EventHandler with each missing, modified and extra file.")
+	var mutex sync.Mutex
+	actions := map[string]static.Action{}
+	err = static.Verify(options, func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		mutex.Lock()
+		defer mutex.Unlock()
+		actions[filepath.Base(e.OutputPath)] = e.Action
+	})
+	t.Logf("Verify() => %v", err)
+	if err == nil {
+		t.Errorf("Verify() => nil, expected an error")
+	}
+	expectedActions := map[string]static.Action{"a": static.MISSING, "b": static.MODIFIED, "d": static.EXTRA}
+	if !reflect.DeepEqual(actions, expectedActions) {
+		t.Errorf("Actions => %v, expected %v", actions, expectedActions)
+	}
+}
@@ -0,0 +1,406 @@
+package static
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// MinifyHTML is a PostProcessor that removes comments from HTML, other than conditional comments, and collapses runs of whitespace outside of tags to a single space. The contents of pre, textarea, script and style elements are left as is.
+var MinifyHTML = PostProcessor{
+	Name:         "html",
+	ContentTypes: []string{"text/html"},
+	Transform:    TransformFunc(minifyHTML),
+}
+
+// MinifyCSS is a PostProcessor that removes comments from CSS, other than comments starting with /*!, collapses runs of whitespace, and removes whitespace and semicolons that are not needed.
+var MinifyCSS = PostProcessor{
+	Name:         "css",
+	ContentTypes: []string{"text/css"},
+	Transform:    TransformFunc(minifyCSS),
+}
+
+// MinifyJS is a PostProcessor that removes comments from JavaScript, other than comments starting with /*!, and removes whitespace that is not needed. Line breaks that automatic semicolon insertion may depend on are kept, identifiers are not renamed, and template literals are kept as is including their substitutions.
+var MinifyJS = PostProcessor{
+	Name:         "js",
+	ContentTypes: []string{"text/javascript", "application/javascript", "application/x-javascript"},
+	Transform:    TransformFunc(minifyJS),
+}
+
+// MinifyJSON is a PostProcessor that removes insignificant whitespace from JSON. Bodies that are not valid JSON are left as is.
+var MinifyJSON = PostProcessor{
+	Name:         "json",
+	ContentTypes: []string{"application/json", "application/*+json"},
+	Transform:    TransformFunc(minifyJSON),
+}
+
+// NormalizeWhitespace is a PostProcessor that converts CRLF line endings to LF, removes trailing spaces and tabs from each line, and ends the body with a single line ending.
+var NormalizeWhitespace = PostProcessor{
+	Name:         "whitespace",
+	ContentTypes: []string{"text/*"},
+	Transform:    TransformFunc(normalizeWhitespace),
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// htmlRawElements are the elements whose contents are left as is by MinifyHTML.
+var htmlRawElements = []string{"pre", "textarea", "script", "style"}
+
+func minifyHTML(b []byte) ([]byte, error) {
+	out := bytes.Buffer{}
+	lower := asciiLower(b)
+	for i := 0; i < len(b); {
+		switch {
+		case bytes.HasPrefix(b[i:], []byte("<!--")):
+			end := bytes.Index(b[i+4:], []byte("-->"))
+			if end < 0 {
+				out.Write(b[i:])
+				i = len(b)
+				break
+			}
+			end = i + 4 + end + 3
+			if bytes.HasPrefix(b[i:], []byte("<!--[if")) || bytes.HasPrefix(b[i:], []byte("<!--<![endif")) {
+				out.Write(b[i:end])
+			}
+			i = end
+		case b[i] == '<':
+			end := htmlTagEnd(b, i)
+			out.Write(b[i:end])
+			if name := htmlRawElement(lower[i:end]); name != "" {
+				closing := bytes.Index(lower[end:], []byte("</"+name))
+				if closing < 0 {
+					out.Write(b[end:])
+					i = len(b)
+					break
+				}
+				closing += end
+				out.Write(b[end:closing])
+				end = htmlTagEnd(b, closing)
+				out.Write(b[closing:end])
+			}
+			i = end
+		case isSpace(b[i]):
+			for i < len(b) && isSpace(b[i]) {
+				i++
+			}
+			out.WriteByte(' ')
+		default:
+			out.WriteByte(b[i])
+			i++
+		}
+	}
+	return bytes.TrimSpace(out.Bytes()), nil
+}
+
+// asciiLower returns a copy of b with ASCII letters in lower case, so that indexes in it are indexes in b.
+func asciiLower(b []byte) []byte {
+	lower := make([]byte, len(b))
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		lower[i] = c
+	}
+	return lower
+}
+
+// htmlTagEnd returns the index after the end of the tag starting at i, skipping quoted attribute values.
+func htmlTagEnd(b []byte, i int) int {
+	var quote byte
+	for j := i + 1; j < len(b); j++ {
+		switch {
+		case quote != 0:
+			if b[j] == quote {
+				quote = 0
+			}
+		case b[j] == '"' || b[j] == '\'':
+			quote = b[j]
+		case b[j] == '>':
+			return j + 1
+		}
+	}
+	return len(b)
+}
+
+// htmlRawElement returns the name of the element opened by the tag if its contents are left as is, otherwise an empty string.
+func htmlRawElement(tag []byte) string {
+	for _, name := range htmlRawElements {
+		if !bytes.HasPrefix(tag, []byte("<"+name)) || len(tag) <= len(name)+1 {
+			continue
+		}
+		c := tag[len(name)+1]
+		if c == '>' || c == '/' || isSpace(c) {
+			return name
+		}
+	}
+	return ""
+}
+
+// skipString writes the string literal starting at i, with the quote at i, and returns the index after it.
+func skipString(out *bytes.Buffer, b []byte, i int) int {
+	j := stringEnd(b, i)
+	out.Write(b[i:j])
+	return j
+}
+
+// stringEnd returns the index after the string literal starting at i, with the quote at i.
+func stringEnd(b []byte, i int) int {
+	quote := b[i]
+	j := i + 1
+	for ; j < len(b); j++ {
+		if b[j] == '\\' {
+			j++
+			continue
+		}
+		if b[j] == quote {
+			j++
+			break
+		}
+	}
+	if j > len(b) {
+		j = len(b)
+	}
+	return j
+}
+
+// skipTemplate writes the template literal starting at i, with the backtick at i, unchanged including its substitutions, and returns the index after it.
+func skipTemplate(out *bytes.Buffer, b []byte, i int) int {
+	j := templateEnd(b, i)
+	out.Write(b[i:j])
+	return j
+}
+
+// templateEnd returns the index after the template literal starting at i, with the backtick at i. Strings, braces and template literals nested in a ${} substitution are skipped so that a } or ` inside them does not end the substitution or the template literal.
+func templateEnd(b []byte, i int) int {
+	for j := i + 1; j < len(b); j++ {
+		switch {
+		case b[j] == '\\':
+			j++
+		case b[j] == '`':
+			return j + 1
+		case b[j] == '$' && j+1 < len(b) && b[j+1] == '{':
+			depth := 0
+			for j++; j < len(b); j++ {
+				switch b[j] {
+				case '"', '\'':
+					j = stringEnd(b, j) - 1
+				case '`':
+					j = templateEnd(b, j) - 1
+				case '{':
+					depth++
+				case '}':
+					depth--
+				}
+				if depth == 0 {
+					break
+				}
+			}
+		}
+	}
+	return len(b)
+}
+
+// skipComment writes the block comment starting at i if it starts with /*!, and returns the index after it.
+func skipComment(out *bytes.Buffer, b []byte, i int) int {
+	end := bytes.Index(b[i+2:], []byte("*/"))
+	j := len(b)
+	if end >= 0 {
+		j = i + 2 + end + 2
+	}
+	if bytes.HasPrefix(b[i:], []byte("/*!")) {
+		out.Write(b[i:j])
+	}
+	return j
+}
+
+func lastByte(out *bytes.Buffer) byte {
+	if out.Len() == 0 {
+		return 0
+	}
+	return out.Bytes()[out.Len()-1]
+}
+
+func minifyCSS(b []byte) ([]byte, error) {
+	const noSpaceAfter = "{};,(>:"
+	const noSpaceBefore = "{};,)>"
+	out := bytes.Buffer{}
+	space := false
+	for i := 0; i < len(b); {
+		c := b[i]
+		switch {
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			start := out.Len()
+			i = skipComment(&out, b, i)
+			if out.Len() == start {
+				space = true
+			}
+			continue
+		case isSpace(c):
+			space = true
+			i++
+			continue
+		}
+		last := lastByte(&out)
+		if space && last != 0 && !strings.ContainsRune(noSpaceAfter, rune(last)) && !strings.ContainsRune(noSpaceBefore, rune(c)) {
+			out.WriteByte(' ')
+		}
+		space = false
+		if c == '}' && last == ';' {
+			out.Truncate(out.Len() - 1)
+		}
+		if c == '"' || c == '\'' {
+			i = skipString(&out, b, i)
+			continue
+		}
+		out.WriteByte(c)
+		i++
+	}
+	return out.Bytes(), nil
+}
+
+// jsPunctuation are the characters that whitespace next to can be removed in JavaScript.
+const jsPunctuation = "{}()[];,=:<>!&|?*%^~"
+
+// jsRegexpKeywords are the keywords that a regular expression literal can follow in JavaScript.
+var jsRegexpKeywords = []string{"return", "typeof", "case", "do", "else", "in", "instanceof", "new", "delete", "void", "throw", "yield", "await"}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+// jsHeadKeywords are the keywords whose parenthesized head is followed by a statement, which can start with a regular expression literal in JavaScript.
+var jsHeadKeywords = []string{"if", "while", "for", "with"}
+
+// jsEndsWithKeyword reports if what has been written ends with one of the keywords.
+func jsEndsWithKeyword(out []byte, keywords []string) bool {
+	out = bytes.TrimRight(out, " \n")
+	for _, keyword := range keywords {
+		if bytes.HasSuffix(out, []byte(keyword)) && (len(out) == len(keyword) || !isIdentByte(out[len(out)-len(keyword)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsRegexpAllowed reports if a / after what has been written starts a regular expression literal rather than a division. afterHead reports if the last ) written closes the head of an if, while, for or with statement.
+func jsRegexpAllowed(out []byte, afterHead bool) bool {
+	out = bytes.TrimRight(out, " \n")
+	if len(out) == 0 {
+		return true
+	}
+	last := out[len(out)-1]
+	if strings.ContainsRune("(,=:[!&|?{};+-*%<>~^", rune(last)) || last == ')' && afterHead {
+		return true
+	}
+	return jsEndsWithKeyword(out, jsRegexpKeywords)
+}
+
+// skipRegexp writes the regular expression literal starting at i, and returns the index after it, not including its flags.
+func skipRegexp(out *bytes.Buffer, b []byte, i int) int {
+	class := false
+	j := i + 1
+	for ; j < len(b) && b[j] != '\n'; j++ {
+		switch {
+		case b[j] == '\\':
+			j++
+		case b[j] == '[':
+			class = true
+		case b[j] == ']':
+			class = false
+		case b[j] == '/' && !class:
+			out.Write(b[i : j+1])
+			return j + 1
+		}
+	}
+	out.Write(b[i:j])
+	return j
+}
+
+func minifyJS(b []byte) ([]byte, error) {
+	out := bytes.Buffer{}
+	space, newline := false, false
+	// Whether each open ( is the head of an if, while, for or with statement, and whether the last ) closed one.
+	var heads []bool
+	afterHead := false
+	for i := 0; i < len(b); {
+		c := b[i]
+		switch {
+		case c == '/' && i+1 < len(b) && b[i+1] == '/':
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+			continue
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			start := out.Len()
+			i = skipComment(&out, b, i)
+			if out.Len() == start {
+				space = true
+			}
+			continue
+		case c == '\n' || c == '\r':
+			newline = true
+			i++
+			continue
+		case isSpace(c):
+			space = true
+			i++
+			continue
+		}
+
+		last := lastByte(&out)
+		switch {
+		case last == 0:
+		case newline && !strings.ContainsRune("{;,(", rune(last)) && !strings.ContainsRune("})", rune(c)):
+			out.WriteByte('\n')
+		case (space || newline) && !strings.ContainsRune(jsPunctuation, rune(last)) && !strings.ContainsRune(jsPunctuation, rune(c)):
+			out.WriteByte(' ')
+		}
+		space, newline = false, false
+
+		switch {
+		case c == '"' || c == '\'':
+			i = skipString(&out, b, i)
+		case c == '`':
+			i = skipTemplate(&out, b, i)
+		case c == '/' && jsRegexpAllowed(out.Bytes(), afterHead):
+			i = skipRegexp(&out, b, i)
+		case c == '(':
+			heads = append(heads, jsEndsWithKeyword(out.Bytes(), jsHeadKeywords))
+			out.WriteByte(c)
+			i++
+		case c == ')':
+			afterHead = len(heads) > 0 && heads[len(heads)-1]
+			if len(heads) > 0 {
+				heads = heads[:len(heads)-1]
+			}
+			out.WriteByte(c)
+			i++
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func minifyJSON(b []byte) ([]byte, error) {
+	out := bytes.Buffer{}
+	if json.Compact(&out, b) != nil {
+		return b, nil
+	}
+	return out.Bytes(), nil
+}
+
+func normalizeWhitespace(b []byte) ([]byte, error) {
+	lines := bytes.Split(bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n")), []byte("\n"))
+	for i, line := range lines {
+		lines[i] = bytes.TrimRight(line, " \t")
+	}
+	out := bytes.TrimRight(bytes.Join(lines, []byte("\n")), "\n")
+	if len(out) == 0 {
+		return out, nil
+	}
+	return append(out, '\n'), nil
+}
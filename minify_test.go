@@ -0,0 +1,47 @@
+package static_test
+
+import (
+	"bytes"
+	"testing"
+
+	"4d63.com/static"
+)
+
+func TestMinify(t *testing.T) {
+	tests := []struct {
+		postProcessor static.PostProcessor
+		body          string
+		expected      string
+	}{
+		{static.MinifyHTML, "<html>\n  <body>\n    <p>Hello,   world!</p>\n  </body>\n</html>\n", "<html> <body> <p>Hello, world!</p> </body> </html>"},
+		{static.MinifyHTML, "<p>a</p><!-- comment --><!--[if IE]><p>b</p><![endif]-->", "<p>a</p><!--[if IE]><p>b</p><![endif]-->"},
+		{static.MinifyHTML, "<PRE>\n  a\n    b\n</PRE>\n<p title=\"a  >  b\">  c  </p>", "<PRE>\n  a\n    b\n</PRE> <p title=\"a  >  b\"> c </p>"},
+		{static.MinifyHTML, "<script>\nvar a = 1;  // comment\n</script>", "<script>\nvar a = 1;  // comment\n</script>"},
+		{static.MinifyCSS, "/* comment */\nbody {\n  color: red;\n  margin: 0 auto;\n}\n\na > b , c { content: \"a  ;  b\"; }\n", "body{color:red;margin:0 auto}a>b,c{content:\"a  ;  b\"}"},
+		{static.MinifyCSS, "/*! license */\n@media (min-width: 100px) and (max-width: 200px) { a { width: calc(1px + 2px); } }", "/*! license */ @media (min-width:100px) and (max-width:200px){a{width:calc(1px + 2px)}}"},
+		{static.MinifyJS, "// comment\nfunction add(a, b) {\n  return a + b; /* sum */\n}\n", "function add(a,b){return a + b;}"},
+		{static.MinifyJS, "var a = 1\nvar b = \"x  //  y\"\nvar c = a / 2 / b\nvar d = /a  b\\//g.test(c)\n", "var a=1\nvar b=\"x  //  y\"\nvar c=a / 2 / b\nvar d=/a  b\\//g.test(c)"},
+		{static.MinifyJS, "if (x) /ab  c/.test(s)\nvar y = (a) / 2 / (b)\n", "if(x)/ab  c/.test(s)\nvar y=(a)/ 2 /(b)"},
+		{static.MinifyJS, "x = `a ${c ? `b  c` : 'd'} e`\n", "x=`a ${c ? `b  c` : 'd'} e`"},
+		{static.MinifyJS, "items.map(i => `<li>  ${i}  </li>`)\n", "items.map(i=>`<li>  ${i}  </li>`)"},
+		{static.MinifyJS, "var s = `a ${ {b: '}'}.b } c`\n", "var s=`a ${ {b: '}'}.b } c`"},
+		{static.MinifyJSON, "{\n  \"a\": [1, 2],\n  \"b\": \"c  d\"\n}\n", "{\"a\":[1,2],\"b\":\"c  d\"}"},
+		{static.MinifyJSON, "{ invalid }", "{ invalid }"},
+		{static.NormalizeWhitespace, "a  \r\nb\t\n\n\n", "a\nb\n"},
+		{static.NormalizeWhitespace, "", ""},
+	}
+
+	for _, test := range tests {
+		out := bytes.Buffer{}
+		w := test.postProcessor.Transform(&out)
+		_, err := w.Write([]byte(test.body))
+		if err == nil {
+			err = w.Close()
+		}
+		if err == nil && out.String() == test.expected {
+			t.Logf("%s.Transform(%q) => %q", test.postProcessor.Name, test.body, out.String())
+		} else {
+			t.Errorf("%s.Transform(%q) => %q, %v, want %q", test.postProcessor.Name, test.body, out.String(), err, test.expected)
+		}
+	}
+}
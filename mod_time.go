@@ -0,0 +1,55 @@
+package static
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// SourceDateEpoch returns the time in the SOURCE_DATE_EPOCH environment variable, the number of seconds since the Unix epoch, for setting as the ModTime of the Options in reproducible builds. Returns the zero time if the variable is not set, or an error if it is not a number. See https://reproducible-builds.org/specs/source-date-epoch/
+func SourceDateEpoch() (time.Time, error) {
+	v := os.Getenv("SOURCE_DATE_EPOCH")
+	if v == "" {
+		return time.Time{}, nil
+	}
+	seconds, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		message := fmt.Sprintf("Unable to parse SOURCE_DATE_EPOCH %s", v)
+		return time.Time{}, buildError{message, err}
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// setModTime sets the modification time of the output file to the Last-Modified time in the response header if the header has one, otherwise to the ModTime of the Options if it is set.
+func setModTime(o Options, outputPath, path string, header http.Header) error {
+	modTime, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		modTime = o.ModTime
+	}
+	if modTime.IsZero() {
+		return nil
+	}
+	err = os.Chtimes(outputPath, modTime, modTime)
+	if err != nil {
+		message := fmt.Sprintf("Unable to set modification time of file %s for path %s", outputPath, path)
+		return buildError{message, err}
+	}
+	return nil
+}
+
+// setDirModTimes sets the modification time of every directory in the OutputDir to the ModTime of the Options, if it is set, so that the directories written to by a build do not have the time of the build.
+func setDirModTimes(o Options) {
+	if o.ModTime.IsZero() {
+		return
+	}
+	filepath.WalkDir(o.OutputDir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			os.Chtimes(path, o.ModTime, o.ModTime)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,77 @@
+package static_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"4d63.com/static"
+)
+
+func TestBuildModesAndModTime(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /dated with a Last-Modified header, and to other paths without.")
+	lastModified := time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/docs/dated" {
+			w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		}
+		w.Write([]byte("Hello!"))
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, a FileMode of 0600, a DirMode of 0700, and a ModTime.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.FileMode = 0600
+	options.DirMode = 0700
+	options.ModTime = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	static.Build(options, handler, []string{"/docs/undated", "/docs/dated"}, nil)
+
+	tests := []struct {
+		file            string
+		expectedMode    os.FileMode
+		expectedModTime time.Time
+	}{
+		{"", os.ModeDir | 0700, options.ModTime},
+		{"docs", os.ModeDir | 0700, options.ModTime},
+		{"docs/undated", 0600, options.ModTime},
+		{"docs/dated", 0600, lastModified},
+	}
+	for _, test := range tests {
+		t.Logf("Expect %q to have mode %v and modification time %v.", test.file, test.expectedMode, test.expectedModTime)
+		path := filepath.Join(options.OutputDir, filepath.FromSlash(test.file))
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat(%s) => %v", path, err)
+		}
+		if fi.Mode() != test.expectedMode || !fi.ModTime().Equal(test.expectedModTime) {
+			t.Errorf("Stat(%s) => %v, %v, expected %v, %v", path, fi.Mode(), fi.ModTime(), test.expectedMode, test.expectedModTime)
+		}
+	}
+}
+
+func TestSourceDateEpoch(t *testing.T) {
+	tests := []struct {
+		value         string
+		expectedTime  time.Time
+		expectedError bool
+	}{
+		{"", time.Time{}, false},
+		{"1577836800", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), false},
+		{"yesterday", time.Time{}, true},
+	}
+	for _, test := range tests {
+		t.Logf("When SOURCE_DATE_EPOCH is %q.", test.value)
+		t.Setenv("SOURCE_DATE_EPOCH", test.value)
+
+		t.Logf("Expect SourceDateEpoch to return %v, and an error %v.", test.expectedTime, test.expectedError)
+		modTime, err := static.SourceDateEpoch()
+		if !modTime.Equal(test.expectedTime) || (err != nil) != test.expectedError {
+			t.Errorf("SourceDateEpoch() => %v, %v, expected %v, error %v", modTime, err, test.expectedTime, test.expectedError)
+		}
+	}
+}
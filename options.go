@@ -1,5 +1,11 @@
 package static
 
+import (
+	"net/http"
+	"os"
+	"time"
+)
+
 // Options for configuring the behavior of the Build functions. Get the default options with DefaultOptions.
 type Options struct {
 	// The directory where files will be written when building.
@@ -8,6 +14,56 @@ type Options struct {
 	Concurrency int
 	// The filename to use when saving directory paths. e.g. index.html
 	DirFilename string
+	// The policy for retrying paths that fail transiently. Nil means paths are not retried.
+	Retry *RetryPolicy
+	// The number of paths that can fail to build before Build stops building the remaining paths. A path built in several locales or variants counts once, failing if any of its builds fail. Zero means there is no limit. e.g. 1 to stop on the first error
+	MaxErrors int
+	// The percentage of paths that can fail to build before Build stops building the remaining paths. When paths come from a sequence, the percentage is of the paths built so far. Zero means there is no limit.
+	MaxErrorPercent float64
+	// The maximum number of paths started per second. A path built in several locales or variants is started once, and its builds are not limited. Zero means there is no limit.
+	RateLimit float64
+	// The number of paths that can be started at once without waiting for the RateLimit. Values less than 1 are treated as 1.
+	RateBurst int
+	// The policy for adjusting the number of paths built concurrently. Nil means Concurrency paths are always built concurrently.
+	AdaptiveConcurrency *AdaptiveConcurrency
+	// Directories of static assets that are copied into the OutputDir before paths are built.
+	Assets []AssetMount
+	// The template for the request made to the handler for each path. Nil means a GET request with no host, headers or cookies.
+	Request *RequestTemplate
+	// A function that can customize the request made to the handler for each path, called after the Request template is applied. Returning an error fails the path. May be nil.
+	PrepareRequest func(r *http.Request) error
+	// The locales to build each path in. Nil means paths are built once.
+	Locales *Locales
+	// A function returning the representations to build for a path, each requested with its own Accept header and written with its own suffix. Nil, or no variants, means each path is built once.
+	Variants func(path string) []Variant
+	// The path in the OutputDir to write a JSON manifest of the variants built for each path. Empty means no manifest is written. e.g. /variants.json
+	VariantManifest string
+	// The policy for building paths whose output file already exists with conditional requests, keeping the output file when the handler responds 304 Not Modified. Nil means output files are always rewritten.
+	Conditional *Conditional
+	// What is done with the output file of a path whose response has no body. The zero value writes an empty file.
+	EmptyBody EmptyBody
+	// The contents of the output file of a path whose response has no body, when EmptyBody is EmptyBodyPlaceholder.
+	EmptyBodyPlaceholder []byte
+	// How characters that are illegal in file names, and names reserved on Windows, are written in output file names. The zero value percent-encodes them.
+	FileNameEncoding FileNameEncoding
+	// The function mapping each path to the file it is written to. Nil means DefaultPathMapper.
+	PathMapper PathMapper
+	// How a path is built when its output file collides with the output file of another path. The zero value fails the path with a CollisionError.
+	Collision Collision
+	// Whether output files that differ only in case do not collide, because the OutputDir and wherever it is deployed are case sensitive.
+	CaseSensitive bool
+	// Whether each path from a Source is remembered until the build finishes, so that later paths from a Source are deduplicated against it and checked for output file collisions with it. This holds every path from a Source in memory. False means paths from a Source are only deduplicated against and checked for collisions with the paths known before the build starts, so that memory stays flat however many paths a Source yields.
+	TrackSourcePaths bool
+	// The permissions of output files, set regardless of the umask. Zero means 0666 before the umask is applied. e.g. 0644
+	FileMode os.FileMode
+	// The permissions of directories created in the OutputDir, set regardless of the umask. Zero means 0755 before the umask is applied.
+	DirMode os.FileMode
+	// The modification time of output files whose response has no Last-Modified header, of assets, and of the directories in the OutputDir, so that two builds of the same content are identical. Zero means the time they are written. e.g. the time from SourceDateEpoch
+	ModTime time.Time
+	// The path in the OutputDir to write a JSON manifest of every file in the OutputDir, with the path it was built from and its size and SHA-256, for checking the OutputDir with Verify. Empty means no manifest is written. e.g. /manifest.json
+	Manifest string
+	// The stages applied in order to the body of each response with a matching Content-Type before it is written to the output file, such as minifiers. Each stage that is applied is reported to the EventHandler with the bytes it saved. e.g. []PostProcessor{MinifyHTML, MinifyCSS}
+	PostProcessors []PostProcessor
 }
 
 // DefaultOptions contain the default recommended Options.
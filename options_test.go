@@ -1,6 +1,7 @@
 package static_test
 
 import (
+	"reflect"
 	"testing"
 
 	"4d63.com/static"
@@ -11,7 +12,7 @@ func TestDefaultOptions(t *testing.T) {
 	expected := static.Options{OutputDir: "build", Concurrency: 50, DirFilename: "index.html"}
 
 	t.Logf("DefaultOptions => %#v", options)
-	if options != expected {
+	if !reflect.DeepEqual(options, expected) {
 		t.Errorf("DefaultOptions => %#v, want %#v", options, expected)
 	}
 }
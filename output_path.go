@@ -0,0 +1,165 @@
+package static
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PathError is the error for a path that is rejected because it cannot be safely written to a file in the OutputDir, such as a path that escapes the OutputDir with .. or contains a NUL byte.
+type PathError struct {
+	// The path that was rejected.
+	Path string
+	// Why the path was rejected. e.g. escapes the OutputDir
+	Reason string
+}
+
+// Error returns the path and why it was rejected as a string.
+func (e PathError) Error() string {
+	return fmt.Sprintf("Path %s rejected: %s", e.Path, e.Reason)
+}
+
+// FileNameEncoding is how characters that are illegal in file names on common filesystems, < > : " \ | ? * and control characters, and names reserved on Windows, such as CON and LPT1, are written in the output file names of paths.
+type FileNameEncoding int
+
+const (
+	// FileNameEncodingPercent percent-encodes illegal characters, and the last character of reserved names. e.g. a:b is written as a%3Ab, and CON as CO%4E
+	FileNameEncodingPercent FileNameEncoding = iota
+	// FileNameEncodingUnderscore replaces illegal characters with an underscore, and appends an underscore to reserved names. e.g. a:b is written as a_b, and CON as CON_
+	FileNameEncodingUnderscore
+	// FileNameEncodingReject rejects paths with illegal characters or reserved names with a PathError.
+	FileNameEncodingReject
+)
+
+// illegalFileNameChars are the characters, other than control characters, that are illegal in file names on common filesystems.
+const illegalFileNameChars = `<>:"\|?*`
+
+// reservedFileNames are the file names reserved on Windows, with or without an extension.
+var reservedFileNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// PathMapper maps a path to the file it is written to, relative to the OutputDir with forward slashes, and without percent-encoding. e.g. /search?page=2 to search/page-2/index.html
+type PathMapper func(o Options, path string) (string, error)
+
+// DefaultPathMapper maps a path to the file of the same name, percent-decoded, or to the DirFilename in the directory of that name if the path ends in a slash. A query string is mapped to a directory for each parameter, sorted by name, containing the DirFilename. Fragments are stripped. e.g. /search?page=2&q=go to search/page-2/q-go/index.html
+func DefaultPathMapper(o Options, path string) (string, error) {
+	if i := strings.IndexByte(path, '#'); i >= 0 {
+		path = path[:i]
+	}
+	path, query, _ := strings.Cut(path, "?")
+
+	// Paths that are not valid percent-encoding are used as is, and fail when the request for them is created.
+	file, err := url.PathUnescape(path)
+	if err != nil {
+		file = path
+	}
+
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return "", fmt.Errorf("invalid query string: %v", err)
+		}
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		file = strings.TrimSuffix(file, "/")
+		for _, key := range keys {
+			for _, value := range values[key] {
+				file += "/" + strings.ReplaceAll(key+"-"+value, "/", "%2F")
+			}
+		}
+		file += "/"
+	}
+
+	if file == "" || strings.HasSuffix(file, "/") {
+		file += o.DirFilename
+	}
+	return file, nil
+}
+
+// OutputFilePath returns the path of the file in the OutputDir that the path is written to, as mapped by the PathMapper of the Options, with each segment encoded with the FileNameEncoding of the Options. Returns a PathError if the path cannot be safely written to a file in the OutputDir.
+func OutputFilePath(o Options, path string) (string, error) {
+	mapper := o.PathMapper
+	if mapper == nil {
+		mapper = DefaultPathMapper
+	}
+	file, err := mapper(o, path)
+	if err != nil {
+		return "", PathError{path, err.Error()}
+	}
+	if strings.ContainsRune(file, 0) {
+		return "", PathError{path, "contains a NUL byte"}
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(file, "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			if len(segments) == 0 {
+				return "", PathError{path, "escapes the OutputDir"}
+			}
+			segments = segments[:len(segments)-1]
+		default:
+			segment, err = encodeFileName(o.FileNameEncoding, segment)
+			if err != nil {
+				return "", PathError{path, err.Error()}
+			}
+			segments = append(segments, segment)
+		}
+	}
+	if len(segments) == 0 || strings.HasSuffix(file, "/") {
+		segments = append(segments, o.DirFilename)
+	}
+
+	outputPath := filepath.Join(append([]string{o.OutputDir}, segments...)...)
+	rel, err := filepath.Rel(o.OutputDir, outputPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", PathError{path, "escapes the OutputDir"}
+	}
+	return outputPath, nil
+}
+
+// encodeFileName encodes the illegal characters and reserved names in the file name with the encoding.
+func encodeFileName(encoding FileNameEncoding, name string) (string, error) {
+	var b strings.Builder
+	for _, r := range name {
+		if r >= 0x20 && r != 0x7f && !strings.ContainsRune(illegalFileNameChars, r) {
+			b.WriteRune(r)
+			continue
+		}
+		switch encoding {
+		case FileNameEncodingReject:
+			return "", fmt.Errorf("contains the illegal character %q", r)
+		case FileNameEncodingUnderscore:
+			b.WriteByte('_')
+		default:
+			fmt.Fprintf(&b, "%%%02X", r)
+		}
+	}
+	name = b.String()
+
+	base := name
+	if i := strings.IndexByte(base, '.'); i >= 0 {
+		base = base[:i]
+	}
+	if !reservedFileNames[strings.ToUpper(base)] {
+		return name, nil
+	}
+	switch encoding {
+	case FileNameEncodingReject:
+		return "", fmt.Errorf("contains the reserved name %s", base)
+	case FileNameEncodingUnderscore:
+		return base + "_" + name[len(base):], nil
+	default:
+		last := len(base) - 1
+		return fmt.Sprintf("%s%%%02X%s", base[:last], base[last], name[len(base):]), nil
+	}
+}
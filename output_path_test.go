@@ -0,0 +1,122 @@
+package static_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"4d63.com/static"
+)
+
+func TestBuildSingleOutputPath(t *testing.T) {
+	t.Log("When a Handler is defined to respond with Hello!")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello!"))
+	})
+
+	tests := []struct {
+		path           string
+		encoding       static.FileNameEncoding
+		expectedFile   string
+		expectedReason string
+	}{
+		{"/", static.FileNameEncodingPercent, "index.html", ""},
+		{"/docs/", static.FileNameEncodingPercent, "docs/index.html", ""},
+		{"/caf%C3%A9", static.FileNameEncodingPercent, "café", ""},
+		{"/a/./b/../c", static.FileNameEncodingPercent, "a/c", ""},
+		{"/a:b", static.FileNameEncodingPercent, "a%3Ab", ""},
+		{"/a%5Cb", static.FileNameEncodingPercent, "a%5Cb", ""},
+		{"/con.txt", static.FileNameEncodingPercent, "co%6E.txt", ""},
+		{"/a:b", static.FileNameEncodingUnderscore, "a_b", ""},
+		{"/LPT1", static.FileNameEncodingUnderscore, "LPT1_", ""},
+		{"/a:b", static.FileNameEncodingReject, "", `contains the illegal character ':'`},
+		{"/aux", static.FileNameEncodingReject, "", "contains the reserved name aux"},
+		{"/search?page=2", static.FileNameEncodingPercent, "search/page-2/index.html", ""},
+		{"/search/?q=go&page=2", static.FileNameEncodingPercent, "search/page-2/q-go/index.html", ""},
+		{"/search?q=a%2Fb", static.FileNameEncodingPercent, "search/q-a%2Fb/index.html", ""},
+		{"/about#team", static.FileNameEncodingPercent, "about", ""},
+		{"/search?page=2#results", static.FileNameEncodingPercent, "search/page-2/index.html", ""},
+		{"/../secret", static.FileNameEncodingPercent, "", "escapes the OutputDir"},
+		{"/a/%2E%2E/%2E%2E/secret", static.FileNameEncodingPercent, "", "escapes the OutputDir"},
+		{"/a%00b", static.FileNameEncodingPercent, "", "contains a NUL byte"},
+	}
+
+	for _, test := range tests {
+		t.Logf("And Options are defined with defaults, an OutputDir that does not exist, and a FileNameEncoding of %d.", test.encoding)
+		options := static.DefaultOptions
+		tempDir, _ := ioutil.TempDir("", "")
+		options.OutputDir = filepath.Join(tempDir, "build")
+		options.FileNameEncoding = test.encoding
+
+		statusCode, outputPath, err := static.BuildSingle(options, handler, test.path)
+		t.Logf("BuildSingle(%q) => %d, %q, %v", test.path, statusCode, outputPath, err)
+
+		if test.expectedReason != "" {
+			t.Logf("Expect BuildSingle of %s to be rejected with a PathError because it %s.", test.path, test.expectedReason)
+			var pathErr static.PathError
+			if !errors.As(err, &pathErr) || pathErr.Path != test.path || pathErr.Reason != test.expectedReason {
+				t.Errorf("BuildSingle(%q) => %#v, expected a PathError with Reason %q", test.path, err, test.expectedReason)
+			}
+			continue
+		}
+
+		t.Logf("Expect BuildSingle of %s to write %s.", test.path, test.expectedFile)
+		expectedOutputPath := filepath.Join(options.OutputDir, filepath.FromSlash(test.expectedFile))
+		if err != nil || outputPath != expectedOutputPath {
+			t.Errorf("BuildSingle(%q) => %q, %v, expected %q, nil", test.path, outputPath, err, expectedOutputPath)
+		}
+		contents, err := ioutil.ReadFile(expectedOutputPath)
+		if err != nil || string(contents) != "Hello!" {
+			t.Errorf("Contents of %s => %s, %v, expected Hello!", expectedOutputPath, contents, err)
+		}
+	}
+}
+
+func TestBuildSingleOutputPathMapper(t *testing.T) {
+	t.Log("When a Handler is defined to respond with the query string.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.RawQuery))
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and a PathMapper that maps the query string to an encoded file name.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.PathMapper = func(o static.Options, path string) (string, error) {
+		path, query, _ := strings.Cut(path, "?")
+		if query == "" {
+			return path, nil
+		}
+		return path + "?" + query + ".html", nil
+	}
+
+	t.Log("Expect OutputFilePath and BuildSingle to use the PathMapper, and encode the illegal character in the mapped file name.")
+	expectedOutputPath := filepath.Join(options.OutputDir, "search%3Fpage=2.html")
+	outputPath, err := static.OutputFilePath(options, "/search?page=2")
+	if err != nil || outputPath != expectedOutputPath {
+		t.Errorf("OutputFilePath() => %q, %v, expected %q, nil", outputPath, err, expectedOutputPath)
+	}
+	_, outputPath, err = static.BuildSingle(options, handler, "/search?page=2")
+	if err != nil || outputPath != expectedOutputPath {
+		t.Errorf("BuildSingle() => %q, %v, expected %q, nil", outputPath, err, expectedOutputPath)
+	}
+	contents, err := ioutil.ReadFile(expectedOutputPath)
+	if err != nil || string(contents) != "page=2" {
+		t.Errorf("Contents of %s => %s, %v, expected page=2", expectedOutputPath, contents, err)
+	}
+
+	t.Log("And when the PathMapper returns an error.")
+	options.PathMapper = func(o static.Options, path string) (string, error) {
+		return "", errors.New("unmapped")
+	}
+
+	t.Log("Expect BuildSingle to return a PathError with the error as the Reason.")
+	_, _, err = static.BuildSingle(options, handler, "/search?page=2")
+	var pathErr static.PathError
+	if !errors.As(err, &pathErr) || pathErr.Reason != "unmapped" {
+		t.Errorf("BuildSingle() => %#v, expected a PathError with Reason unmapped", err)
+	}
+}
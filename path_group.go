@@ -0,0 +1,334 @@
+package static
+
+import (
+	"fmt"
+	"iter"
+	"sort"
+	"sync"
+)
+
+// PathGroup is a set of paths that are scheduled together by BuildGroups.
+type PathGroup struct {
+	// The name of the group, used by other groups to depend on it.
+	Name string
+	// The paths to build.
+	Paths []string
+	// A source of further paths to build, listed while the build is running. Paths from the Source are built after Paths. May be nil.
+	Source iter.Seq[string]
+	// The number of paths in the group that can be built concurrently. Zero means the group is only limited by the Options Concurrency.
+	Concurrency int
+	// Paths in groups with a higher priority are built before paths in groups with a lower priority.
+	Priority int
+	// The names of the groups that must finish building before any path in this group is built.
+	DependsOn []string
+
+	// The site the paths are built for, when built with BuildSites.
+	site *Site
+}
+
+// PathsFromChan returns a sequence of the paths received from the channel, that ends when the channel is closed. Use with BuildSeq or a PathGroup Source to build paths sent on a channel.
+func PathsFromChan(paths <-chan string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for path := range paths {
+			if !yield(path) {
+				return
+			}
+		}
+	}
+}
+
+// scheduledPath is a path handed to a worker, along with the group it belongs to.
+type scheduledPath struct {
+	path     string
+	group    *scheduledGroup
+	streamed bool
+}
+
+// scheduledGroup is the scheduling state of a PathGroup.
+type scheduledGroup struct {
+	PathGroup
+	dependsOn   []*scheduledGroup
+	next        int
+	building    int
+	stream      chan string
+	unscheduled []string
+	unlisted    []string
+}
+
+// ready reports if all the groups this group depends on have finished building.
+func (g *scheduledGroup) ready() bool {
+	for _, d := range g.dependsOn {
+		if !d.finished() {
+			return false
+		}
+	}
+	return true
+}
+
+// listed reports if every path in the group has been handed out.
+func (g *scheduledGroup) listed() bool {
+	return g.next == len(g.Paths) && g.stream == nil
+}
+
+// finished reports if every path in the group has been built.
+func (g *scheduledGroup) finished() bool {
+	return g.listed() && g.building == 0
+}
+
+// scheduler hands out the paths of groups in priority order, while respecting the concurrency and dependencies of each group.
+type scheduler struct {
+	mutex    sync.Mutex
+	cond     *sync.Cond
+	groups   []*scheduledGroup
+	stopping chan struct{}
+	stopped  bool
+	// The canonical paths scheduled, prefixed with the host of the group's site, and the duplicates not scheduled.
+	scheduled  map[string]bool
+	duplicates []duplicatePath
+	// Whether paths from a Source are remembered as scheduled, as defined by the Options TrackSourcePaths.
+	trackStreamed bool
+}
+
+// newScheduler returns a scheduler for the groups, or an error if the groups have duplicate names or depend on groups that are unknown or depend on them in a cycle. Paths from each group's Source are listed ahead of being built, holding no more than buffer paths in memory per group.
+func newScheduler(groups []PathGroup, buffer int) (*scheduler, error) {
+	s := &scheduler{stopping: make(chan struct{}), scheduled: map[string]bool{}}
+	s.cond = sync.NewCond(&s.mutex)
+
+	byName := map[string]*scheduledGroup{}
+	for _, pg := range groups {
+		g := &scheduledGroup{PathGroup: pg}
+		if _, exists := byName[g.Name]; exists {
+			message := fmt.Sprintf("Duplicate path group %q", g.Name)
+			return nil, buildError{message, nil}
+		}
+		byName[g.Name] = g
+		s.groups = append(s.groups, g)
+
+		g.Paths = make([]string, 0, len(pg.Paths))
+		for _, path := range pg.Paths {
+			if canonical, ok := s.dedupe(g, path, true); ok {
+				g.Paths = append(g.Paths, canonical)
+			}
+		}
+	}
+
+	for _, g := range s.groups {
+		for _, name := range g.PathGroup.DependsOn {
+			d := byName[name]
+			if d == nil {
+				message := fmt.Sprintf("Path group %q depends on unknown path group %q", g.Name, name)
+				return nil, buildError{message, nil}
+			}
+			g.dependsOn = append(g.dependsOn, d)
+		}
+	}
+
+	visited := map[*scheduledGroup]int{}
+	var visit func(g *scheduledGroup) error
+	visit = func(g *scheduledGroup) error {
+		switch visited[g] {
+		case 1:
+			message := fmt.Sprintf("Path group %q depends on itself", g.Name)
+			return buildError{message, nil}
+		case 2:
+			return nil
+		}
+		visited[g] = 1
+		for _, d := range g.dependsOn {
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		visited[g] = 2
+		return nil
+	}
+	for _, g := range s.groups {
+		if err := visit(g); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(s.groups, func(i, j int) bool {
+		return s.groups[i].Priority > s.groups[j].Priority
+	})
+
+	for _, g := range s.groups {
+		if g.Source != nil {
+			g.stream = make(chan string, buffer)
+			go s.list(g)
+		}
+	}
+
+	return s, nil
+}
+
+// list sends the paths yielded by the group's Source to its stream, until the Source is exhausted or the scheduler is stopped.
+func (s *scheduler) list(g *scheduledGroup) {
+	stream := g.stream
+	defer func() {
+		close(stream)
+		s.mutex.Lock()
+		s.cond.Broadcast()
+		s.mutex.Unlock()
+	}()
+
+	unlist := func(path string) {
+		s.mutex.Lock()
+		g.unlisted = append(g.unlisted, path)
+		s.mutex.Unlock()
+	}
+
+	for path := range g.Source {
+		// Check for the stop first, because a select with both ready chooses at random.
+		select {
+		case <-s.stopping:
+			unlist(path)
+			return
+		default:
+		}
+		select {
+		case stream <- path:
+		case <-s.stopping:
+			unlist(path)
+			return
+		}
+		s.mutex.Lock()
+		s.cond.Broadcast()
+		s.mutex.Unlock()
+	}
+}
+
+// stop stops listing paths from the groups' sources, and wakes next so that it returns no more paths. Calling stop again does nothing.
+func (s *scheduler) stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	close(s.stopping)
+	s.cond.Broadcast()
+}
+
+// total returns the number of paths in all groups, or -1 if a group has a Source and the number is not known.
+func (s *scheduler) total() int {
+	total := 0
+	for _, g := range s.groups {
+		if g.Source != nil {
+			return -1
+		}
+		total += len(g.Paths)
+	}
+	return total
+}
+
+// next blocks until a path can be built, and returns it. Returns false when there are no paths left to build, or the scheduler has been stopped.
+func (s *scheduler) next() (scheduledPath, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+scan:
+	for {
+		if s.stopped {
+			return scheduledPath{}, false
+		}
+		remaining := false
+		for _, g := range s.groups {
+			if g.listed() {
+				continue
+			}
+			remaining = true
+			if !g.ready() || (g.Concurrency > 0 && g.building >= g.Concurrency) {
+				continue
+			}
+			if g.next < len(g.Paths) {
+				path := g.Paths[g.next]
+				g.next++
+				g.building++
+				return scheduledPath{path: path, group: g}, true
+			}
+			select {
+			case path, ok := <-g.stream:
+				if !ok {
+					g.stream = nil
+					s.cond.Broadcast()
+					continue scan
+				}
+				canonical, ok := s.dedupe(g, path, s.trackStreamed)
+				if !ok {
+					continue scan
+				}
+				g.building++
+				return scheduledPath{path: canonical, group: g, streamed: true}, true
+			default:
+			}
+		}
+		if !remaining {
+			return scheduledPath{}, false
+		}
+		s.cond.Wait()
+	}
+}
+
+// unschedule returns a path handed out by next that was never built.
+func (s *scheduler) unschedule(p scheduledPath) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if p.streamed {
+		p.group.unscheduled = append(p.group.unscheduled, p.path)
+	} else {
+		p.group.next--
+	}
+	p.group.building--
+}
+
+// done marks a path handed out by next as built.
+func (s *scheduler) done(p scheduledPath) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p.group.building--
+	s.cond.Broadcast()
+}
+
+// unbuilt returns the paths that have not been handed out, in the order they would have been. Must be called after stop. Paths that a Source had not yet yielded are not included, and a Source is not waited on to yield more.
+func (s *scheduler) unbuilt() []string {
+	s.mutex.Lock()
+	streams := make([]chan string, len(s.groups))
+	for i, g := range s.groups {
+		streams[i] = g.stream
+	}
+	s.mutex.Unlock()
+
+	streamed := make([][]string, len(streams))
+	for i, stream := range streams {
+		if stream == nil {
+			continue
+		}
+	drain:
+		for {
+			select {
+			case path, ok := <-stream:
+				if !ok {
+					break drain
+				}
+				streamed[i] = append(streamed[i], path)
+			default:
+				break drain
+			}
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var unbuilt []string
+	for i, g := range s.groups {
+		unbuilt = append(unbuilt, g.Paths[g.next:]...)
+		unbuilt = append(unbuilt, g.unscheduled...)
+		unbuilt = append(unbuilt, streamed[i]...)
+		unbuilt = append(unbuilt, g.unlisted...)
+	}
+	return unbuilt
+}
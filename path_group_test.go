@@ -0,0 +1,127 @@
+package static_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"4d63.com/static"
+)
+
+func TestBuildGroupsPriorityAndDependencies(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /* and response with Hello <path>!")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+	})
+
+	t.Log("And Options are defined with defaults, a Concurrency of 1 and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	options.Concurrency = 1
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And there is a high priority index group that depends on an articles group, and a low priority pages group.")
+	groups := []static.PathGroup{
+		{Name: "pages", Paths: []string{"/about", "/contact"}, Priority: 1},
+		{Name: "index", Paths: []string{"/articles/"}, Priority: 3, DependsOn: []string{"articles"}},
+		{Name: "articles", Paths: []string{"/articles/1", "/articles/2"}, Priority: 2},
+	}
+
+	t.Log("Expect BuildGroups to build the articles first, and the index after the articles and before the last page.")
+	var built []string
+	err := static.BuildGroups(options, handler, groups, func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		built = append(built, e.Path)
+	})
+	if err != nil {
+		t.Fatalf("BuildGroups() => %v, expected nil", err)
+	}
+
+	index := map[string]int{}
+	for i, path := range built {
+		index[path] = i
+	}
+	if len(built) != 5 || index["/articles/1"] != 0 || index["/articles/2"] != 1 || index["/articles/"] < 2 || index["/articles/"] > index["/contact"] {
+		t.Errorf("Paths built => %v, expected the articles first, and the index after the articles and before /contact", built)
+	}
+}
+
+func TestBuildGroupsConcurrency(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /search/* slowly while recording how many it is serving at once.")
+	var mutex sync.Mutex
+	serving, maxServing := 0, 0
+	handler := http.NewServeMux()
+	handler.HandleFunc("/search/", func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		serving++
+		if serving > maxServing {
+			maxServing = serving
+		}
+		mutex.Unlock()
+		time.Sleep(time.Millisecond)
+		mutex.Lock()
+		serving--
+		mutex.Unlock()
+	})
+	handler.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Log("And Options are defined with defaults and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And there is a search group with a Concurrency of 2 and a pages group with no limit.")
+	search := static.PathGroup{Name: "search", Concurrency: 2}
+	pages := static.PathGroup{Name: "pages"}
+	for i := 0; i < 10; i++ {
+		search.Paths = append(search.Paths, fmt.Sprintf("/search/%d", i))
+		pages.Paths = append(pages.Paths, fmt.Sprintf("/page/%d", i))
+	}
+
+	t.Log("Expect BuildGroups to build every path, and never serve more than 2 search paths at once.")
+	var builds int
+	err := static.BuildGroups(options, handler, []static.PathGroup{search, pages}, func(e static.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		builds++
+	})
+	if err != nil {
+		t.Fatalf("BuildGroups() => %v, expected nil", err)
+	}
+	if builds != 20 {
+		t.Errorf("Number of events received => %d, expected %d", builds, 20)
+	}
+	if maxServing > 2 {
+		t.Errorf("Search paths served at once => %d, expected at most 2", maxServing)
+	}
+}
+
+func TestBuildGroupsInvalidDependencies(t *testing.T) {
+	handler := http.NewServeMux()
+
+	tests := []struct {
+		groups      []static.PathGroup
+		expectedErr string
+	}{
+		{[]static.PathGroup{{Name: "a"}, {Name: "a"}}, `Duplicate path group "a"`},
+		{[]static.PathGroup{{Name: "a", DependsOn: []string{"b"}}}, `Path group "a" depends on unknown path group "b"`},
+		{[]static.PathGroup{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"a"}}}, `depends on itself`},
+	}
+
+	for _, test := range tests {
+		err := static.BuildGroups(static.DefaultOptions, handler, test.groups, nil)
+		if err != nil && strings.Contains(err.Error(), test.expectedErr) {
+			t.Logf("BuildGroups(%#v) => %v", test.groups, err)
+		} else {
+			t.Errorf("BuildGroups(%#v) => %v, expected a %s error", test.groups, err, test.expectedErr)
+		}
+	}
+}
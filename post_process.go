@@ -0,0 +1,90 @@
+package static
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	pathpkg "path"
+)
+
+// PostProcessor is a stage of post-processing applied to the bodies of responses with a matching Content-Type, between the handler and the output file, such as a minifier.
+type PostProcessor struct {
+	// The name of the stage, reported in Events. e.g. html
+	Name string
+	// The media types of the responses the stage applies to. A subtype of * matches any subtype, and a subtype of *+suffix any subtype with the suffix. Empty means every response. e.g. text/html, text/*, application/*+json
+	ContentTypes []string
+	// A function returning an io.WriteCloser that transforms what is written to it and writes the result to the io.Writer. Close is called after the whole body is written, and must write anything remaining. An error from Write or Close fails the path.
+	Transform func(w io.Writer) io.WriteCloser
+}
+
+// matches reports if the stage applies to responses with the Content-Type.
+func (p PostProcessor) matches(contentType string) bool {
+	if len(p.ContentTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range p.ContentTypes {
+		if matched, _ := pathpkg.Match(pattern, mediaType); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// postProcessResult is the bytes saved by a stage of post-processing.
+type postProcessResult struct {
+	name  string
+	saved int64
+}
+
+// postProcess applies the PostProcessors of the Options that match the Content-Type to the body in order, returning the processed body and the bytes saved by each stage that was applied.
+func postProcess(o Options, path, contentType string, body []byte) ([]byte, []postProcessResult, error) {
+	var results []postProcessResult
+	if len(body) == 0 {
+		return body, nil, nil
+	}
+	for _, p := range o.PostProcessors {
+		if !p.matches(contentType) {
+			continue
+		}
+		out := bytes.Buffer{}
+		w := p.Transform(&out)
+		_, err := w.Write(body)
+		if err == nil {
+			err = w.Close()
+		}
+		if err != nil {
+			message := fmt.Sprintf("Unable to post-process path %s with %s", path, p.Name)
+			return nil, nil, buildError{message, err}
+		}
+		results = append(results, postProcessResult{p.Name, int64(len(body) - out.Len())})
+		body = out.Bytes()
+	}
+	return body, results, nil
+}
+
+// TransformFunc returns a Transform for a PostProcessor that buffers the whole body and transforms it with the function when closed.
+func TransformFunc(f func(body []byte) ([]byte, error)) func(w io.Writer) io.WriteCloser {
+	return func(w io.Writer) io.WriteCloser {
+		return &bufferedTransform{w: w, f: f}
+	}
+}
+
+type bufferedTransform struct {
+	bytes.Buffer
+	w io.Writer
+	f func(body []byte) ([]byte, error)
+}
+
+func (t *bufferedTransform) Close() error {
+	body, err := t.f(t.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = t.w.Write(body)
+	return err
+}
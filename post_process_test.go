@@ -0,0 +1,127 @@
+package static_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"4d63.com/static"
+)
+
+type upperWriter struct {
+	w io.Writer
+}
+
+func (u upperWriter) Write(p []byte) (int, error) {
+	return u.w.Write(bytes.ToUpper(p))
+}
+
+func (u upperWriter) Close() error {
+	return nil
+}
+
+func TestBuildPostProcessors(t *testing.T) {
+	t.Log("When a Handler is defined to respond to /page with indented HTML, and to /data with indented JSON.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<p>\n  Hello!\n</p>\n")
+		case "/data":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, "{\n  \"a\": 1\n}\n")
+		}
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and PostProcessors MinifyHTML, MinifyJSON and a custom transform that upper cases HTML.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	upper := static.PostProcessor{
+		Name:         "upper",
+		ContentTypes: []string{"text/*"},
+		Transform:    func(w io.Writer) io.WriteCloser { return upperWriter{w} },
+	}
+	options.PostProcessors = []static.PostProcessor{static.MinifyHTML, static.MinifyJSON, upper}
+
+	t.Log("Expect Build to write the processed bodies, and report the bytes saved by each PostProcessor applied and in total.")
+	var mutex sync.Mutex
+	var events []static.Event
+	err := static.Build(options, handler, []string{"/page", "/data"}, func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, e)
+	})
+	if err != nil {
+		t.Fatalf("Build() => %v, expected no error", err)
+	}
+
+	expectedContents := map[string]string{
+		"page": "<P> HELLO! </P>",
+		"data": "{\"a\":1}",
+	}
+	for file, expected := range expectedContents {
+		contents, err := ioutil.ReadFile(filepath.Join(options.OutputDir, file))
+		if err != nil || string(contents) != expected {
+			t.Errorf("Contents of %s => %q, %v, expected %q", file, contents, err, expected)
+		}
+	}
+
+	type key struct {
+		action        static.Action
+		path          string
+		postProcessor string
+	}
+	saved := map[key]int64{}
+	for _, e := range events {
+		saved[key{e.Action, e.Path, e.PostProcessor}] = e.BytesSaved
+	}
+	expectedSaved := map[key]int64{
+		{static.POSTPROCESS, "/page", "html"}:  3,
+		{static.POSTPROCESS, "/page", "upper"}: 0,
+		{static.BUILD, "/page", ""}:            3,
+		{static.POSTPROCESS, "/data", "json"}:  6,
+		{static.BUILD, "/data", ""}:            6,
+	}
+	if len(saved) != len(expectedSaved) {
+		t.Errorf("Events => %v, expected %d distinct events", events, len(expectedSaved))
+	}
+	for k, expected := range expectedSaved {
+		if s, ok := saved[k]; !ok || s != expected {
+			t.Errorf("BytesSaved of %s event for %s by %q => %d, %v, expected %d", k.action, k.path, k.postProcessor, s, ok, expected)
+		}
+	}
+}
+
+func TestBuildSinglePostProcessorError(t *testing.T) {
+	t.Log("When a Handler is defined to respond with Hello!")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello!")
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and a PostProcessor that fails.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.PostProcessors = []static.PostProcessor{{
+		Name: "fail",
+		Transform: static.TransformFunc(func(body []byte) ([]byte, error) {
+			return nil, errors.New("failed")
+		}),
+	}}
+
+	t.Log("Expect BuildSingle to return an error naming the PostProcessor.")
+	_, _, err := static.BuildSingle(options, handler, "/path")
+	t.Logf("BuildSingle() => %v", err)
+	expectedError := "Unable to post-process path /path with fail: failed"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Error => %v, expected %q", err, expectedError)
+	}
+}
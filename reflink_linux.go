@@ -0,0 +1,34 @@
+//go:build linux
+// +build linux
+
+package static
+
+import (
+	"os"
+	"syscall"
+)
+
+// ficlone is the FICLONE ioctl request, which shares the data of one file with another on file systems that support copy-on-write.
+const ficlone = 0x40049409
+
+// reflink creates the file at dst as a copy-on-write clone of the file at src.
+func reflink(src, dst string) error {
+	s, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	d, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return err
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, d.Fd(), ficlone, s.Fd())
+	if errno != 0 {
+		d.Close()
+		os.Remove(dst)
+		return errno
+	}
+	return d.Close()
+}
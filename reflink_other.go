@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package static
+
+import (
+	"errors"
+)
+
+// reflink is not supported on this platform, and always returns an error.
+func reflink(src, dst string) error {
+	return errors.New("reflinks are not supported on this platform")
+}
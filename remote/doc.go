@@ -0,0 +1,2 @@
+// Package remote builds websites with `4d63.com/static` from running web servers, including those not written in Go, by making requests to them.
+package remote // import "4d63.com/static/remote"
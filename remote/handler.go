@@ -0,0 +1,116 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// hopHeaders are headers that apply to a single connection and are not passed through.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Handler is a http.Handler that serves each request by making it to the server at a base URL, passing through the status code, headers and body of the response. Redirects are passed through rather than followed. The body is read in full before the response is written, so that a response cut short is never passed through as a success. When the server cannot be reached or the body cannot be read the status code is 502 Bad Gateway, and when the request times out it is 504 Gateway Timeout, so that both can be retried with a static.RetryPolicy.
+type Handler struct {
+	base   *url.URL
+	client *http.Client
+}
+
+// NewHandler returns a Handler for the server at the base URL. e.g. http://localhost:8080
+func NewHandler(base string, o Options) (*Handler, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to parse URL %s: %v", base, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("Unable to use URL %s: must be absolute, e.g. http://localhost:8080", base)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	u.RawPath = ""
+
+	transport := o.Transport
+	if transport == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.MaxIdleConnsPerHost = o.MaxIdleConnsPerHost
+		transport = t
+	}
+
+	client := &http.Client{
+		Transport: transport,
+		Timeout:   o.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return &Handler{base: u, client: client}, nil
+}
+
+// ServeHTTP makes the request to the server and writes its response.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u := *h.base
+	// The escaped path is passed through as is, so that an escaped / in a path is not unescaped and sent as a path separator.
+	u.RawPath = h.base.EscapedPath() + r.URL.EscapedPath()
+	u.Path += r.URL.Path
+	u.RawQuery = r.URL.RawQuery
+
+	req, err := http.NewRequestWithContext(r.Context(), r.Method, u.String(), nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	for k, v := range r.Header {
+		req.Header[k] = v
+	}
+	removeHopHeaders(req.Header)
+	if r.Host != "" {
+		req.Host = r.Host
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		http.Error(w, err.Error(), gatewayStatus(err))
+		return
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), gatewayStatus(err))
+		return
+	}
+
+	header := w.Header()
+	for k, v := range resp.Header {
+		header[k] = v
+	}
+	removeHopHeaders(header)
+	w.WriteHeader(resp.StatusCode)
+	w.Write(body)
+}
+
+// gatewayStatus returns the status code for an error making a request to the server, 504 Gateway Timeout if it timed out, otherwise 502 Bad Gateway.
+func gatewayStatus(err error) int {
+	var netErr interface{ Timeout() bool }
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusBadGateway
+}
+
+func removeHopHeaders(header http.Header) {
+	for _, h := range hopHeaders {
+		header.Del(h)
+	}
+}
@@ -0,0 +1,171 @@
+package remote_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"4d63.com/static"
+	"4d63.com/static/remote"
+)
+
+func TestHandler(t *testing.T) {
+	t.Log("When a server is running under /app that responds with headers, escaped paths, statuses, redirects, slow responses, and responses that stall part way through the body.")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/app/hello/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("X-Accept-Language", r.Header.Get("Accept-Language"))
+		fmt.Fprintf(w, "Hello %s%s!", filepath.Base(r.URL.Path), r.URL.RawQuery)
+	})
+	handler.HandleFunc("/app/raw/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.EscapedPath())
+	})
+	handler.HandleFunc("/app/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	handler.HandleFunc("/app/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/app/new", http.StatusMovedPermanently)
+	})
+	handler.HandleFunc("/app/slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	})
+	handler.HandleFunc("/app/stalled", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html>partial")
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, "</html>")
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	t.Log("And a Handler for the server's /app/ URL with a Timeout of 20ms.")
+	options := remote.DefaultOptions
+	options.Timeout = 20 * time.Millisecond
+	options.Transport = server.Client().Transport
+	h, err := remote.NewHandler(server.URL+"/app/", options)
+	if err != nil {
+		t.Fatalf("NewHandler() => %v", err)
+	}
+
+	tests := []struct {
+		path           string
+		header         http.Header
+		expectedStatus int
+		expectedHeader string
+		expectedValue  string
+		expectedBody   string
+	}{
+		{"/hello/world?x=1", http.Header{"Accept-Language": {"fr"}}, 200, "X-Accept-Language", "fr", "Hello worldx=1!"},
+		{"/hello/world", nil, 200, "Content-Type", "text/plain", "Hello world!"},
+		{"/raw/a%2Fb", nil, 200, "", "", "/app/raw/a%2Fb"},
+		{"/missing", nil, 404, "Content-Type", "text/plain; charset=utf-8", "404 page not found\n"},
+		{"/old", nil, 301, "Location", "/app/new", "<a href=\"/app/new\">Moved Permanently</a>.\n\n"},
+		{"/slow", nil, 504, "", "", ""},
+		{"/stalled", nil, 504, "", "", ""},
+	}
+
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", test.path, nil)
+		for k, v := range test.header {
+			r.Header[k] = v
+		}
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, r)
+		t.Logf("ServeHTTP(%s) => %d, %v, %q", test.path, rw.Code, rw.Header(), rw.Body.String())
+		if rw.Code != test.expectedStatus {
+			t.Errorf("ServeHTTP(%s) status => %d, expected %d", test.path, rw.Code, test.expectedStatus)
+		}
+		if test.expectedHeader != "" && rw.Header().Get(test.expectedHeader) != test.expectedValue {
+			t.Errorf("ServeHTTP(%s) %s => %s, expected %s", test.path, test.expectedHeader, rw.Header().Get(test.expectedHeader), test.expectedValue)
+		}
+		if test.expectedStatus < 500 && rw.Body.String() != test.expectedBody {
+			t.Errorf("ServeHTTP(%s) body => %q, expected %q", test.path, rw.Body.String(), test.expectedBody)
+		}
+	}
+}
+
+func TestBuildSingleStalled(t *testing.T) {
+	t.Log("When a server is running that flushes the start of a page and then stalls past the Timeout.")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html>partial")
+		w.(http.Flusher).Flush()
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprint(w, "</html>")
+	}))
+	defer server.Close()
+
+	t.Log("And Options are defined with defaults and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+
+	t.Log("Expect BuildSingle with a Handler for the server with a Timeout of 20ms to report 504 Gateway Timeout rather than the truncated page.")
+	remoteOptions := remote.DefaultOptions
+	remoteOptions.Timeout = 20 * time.Millisecond
+	h, _ := remote.NewHandler(server.URL, remoteOptions)
+	status, outputPath, err := static.BuildSingle(options, h, "/page")
+	t.Logf("BuildSingle(/page) => %v, %v, %v", status, outputPath, err)
+	if status != 504 {
+		t.Errorf("BuildSingle(/page) status => %v, expected 504", status)
+	}
+	contents, _ := ioutil.ReadFile(outputPath)
+	if string(contents) == "<html>partial" {
+		t.Errorf("Contents of %s => %s, expected not the truncated page", outputPath, contents)
+	}
+}
+
+func TestHandlerServerDown(t *testing.T) {
+	t.Log("When a server has been stopped.")
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	t.Log("Expect the Handler to respond with 502 Bad Gateway.")
+	h, err := remote.NewHandler(server.URL, remote.DefaultOptions)
+	if err != nil {
+		t.Fatalf("NewHandler() => %v", err)
+	}
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, httptest.NewRequest("GET", "/", nil))
+	if rw.Code != 502 {
+		t.Errorf("ServeHTTP(/) status => %d, expected 502", rw.Code)
+	}
+}
+
+func TestNewHandlerErrors(t *testing.T) {
+	for _, base := range []string{"localhost:8080/", "/path", "http://%zz"} {
+		h, err := remote.NewHandler(base, remote.DefaultOptions)
+		if err == nil {
+			t.Errorf("NewHandler(%#v) => %v, %v, expected an error", base, h, err)
+		}
+	}
+}
+
+func TestBuildSingle(t *testing.T) {
+	t.Log("When a server is running that responds to /hello/* with Hello <path>!")
+	handler := http.NewServeMux()
+	handler.HandleFunc("/hello/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	t.Log("And Options are defined with defaults and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+
+	t.Log("Expect BuildSingle with a Handler for the server to write the server's response.")
+	h, _ := remote.NewHandler(server.URL, remote.DefaultOptions)
+	status, outputPath, err := static.BuildSingle(options, h, "/hello/world")
+	if status != 200 || err != nil {
+		t.Errorf("BuildSingle(/hello/world) => %v, %v, %v, expected 200, nil", status, outputPath, err)
+	}
+	contents, err := ioutil.ReadFile(outputPath)
+	if err != nil || string(contents) != "Hello world!" {
+		t.Errorf("Contents of %s => %s, %v, expected Hello world!", outputPath, contents, err)
+	}
+}
@@ -0,0 +1,22 @@
+package remote
+
+import (
+	"net/http"
+	"time"
+)
+
+// Options for configuring the behavior of a Handler. Get the default options with DefaultOptions.
+type Options struct {
+	// The time limit for each request, including reading the response body. Zero means no limit.
+	Timeout time.Duration
+	// The number of idle connections kept open to the server for reuse. Set it to at least the Concurrency of the build.
+	MaxIdleConnsPerHost int
+	// The transport used to make requests. e.g. the Transport of a httptest.Server Client. Nil means a transport is created using MaxIdleConnsPerHost.
+	Transport http.RoundTripper
+}
+
+// DefaultOptions contain the default recommended Options.
+var DefaultOptions = Options{
+	Timeout:             30 * time.Second,
+	MaxIdleConnsPerHost: 50,
+}
@@ -0,0 +1,70 @@
+package static
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+// RequestTemplate for configuring the requests made to the handler for each path.
+type RequestTemplate struct {
+	// The HTTP method. Empty means GET.
+	Method string
+	// The host the request is made to, set as the Request Host. e.g. example.com
+	Host string
+	// Set to https to make requests as if over TLS, with the Request TLS set.
+	Scheme string
+	// Headers added to each request. e.g. Accept-Language, X-Forwarded-Proto
+	Header http.Header
+	// Cookies added to each request.
+	Cookies []*http.Cookie
+	// The network address of the client. e.g. 127.0.0.1:1234
+	RemoteAddr string
+}
+
+// newRequest returns the request made to the handler for the path, as configured by the Options Request and PrepareRequest.
+func newRequest(o Options, path string) (*http.Request, error) {
+	t := o.Request
+	method := "GET"
+	if t != nil && t.Method != "" {
+		method = t.Method
+	}
+
+	r, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		message := fmt.Sprintf("Unable to create http.Request for path %s", path)
+		return nil, buildError{message, err}
+	}
+
+	if t != nil {
+		if t.Host != "" {
+			r.Host = t.Host
+		}
+		if t.Scheme == "https" {
+			r.TLS = &tls.ConnectionState{
+				Version:           tls.VersionTLS13,
+				HandshakeComplete: true,
+				ServerName:        t.Host,
+			}
+		}
+		for k, v := range t.Header {
+			r.Header[k] = append([]string(nil), v...)
+		}
+		for _, c := range t.Cookies {
+			r.AddCookie(c)
+		}
+		if t.RemoteAddr != "" {
+			r.RemoteAddr = t.RemoteAddr
+		}
+	}
+
+	if o.PrepareRequest != nil {
+		err = o.PrepareRequest(r)
+		if err != nil {
+			message := fmt.Sprintf("Unable to prepare http.Request for path %s", path)
+			return nil, buildError{message, err}
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,73 @@
+package static_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"4d63.com/static"
+)
+
+func TestBuildSingleRequestTemplate(t *testing.T) {
+	t.Log("When a Handler is defined to respond with the details of the request.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session, _ := r.Cookie("session")
+		fmt.Fprintf(w, "%s %s %s tls=%v lang=%s proto=%s session=%s remote=%s", r.Method, r.Host, r.URL.Path, r.TLS != nil, r.Header.Get("Accept-Language"), r.Header.Get("X-Forwarded-Proto"), session.Value, r.RemoteAddr)
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and a Request template and PrepareRequest function.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Request = &static.RequestTemplate{
+		Host:       "example.com",
+		Scheme:     "https",
+		Header:     http.Header{"Accept-Language": {"fr"}},
+		Cookies:    []*http.Cookie{{Name: "session", Value: "abc"}},
+		RemoteAddr: "127.0.0.1:1234",
+	}
+	options.PrepareRequest = func(r *http.Request) error {
+		r.Header.Set("X-Forwarded-Proto", "https")
+		return nil
+	}
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("Expect BuildSingle to make the request with the template and customization applied.")
+	path := "/hello"
+	expectedOutputFileContents := "GET example.com /hello tls=true lang=fr proto=https session=abc remote=127.0.0.1:1234"
+	status, outputPath, err := static.BuildSingle(options, handler, path)
+	if status != 200 || err != nil {
+		t.Errorf("BuildSingle(%#v) => %v, %v, %v, expected 200, nil", path, status, outputPath, err)
+	}
+	outputFileContents, err := ioutil.ReadFile(outputPath)
+	if err != nil || string(outputFileContents) != expectedOutputFileContents {
+		t.Errorf("Contents of %s => %s, %v, expected %s", outputPath, outputFileContents, err, expectedOutputFileContents)
+	}
+}
+
+func TestBuildSingleErrorsPrepareRequest(t *testing.T) {
+	t.Log("When a Handler is defined to respond with Hello!")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "Hello!")
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and a PrepareRequest function that errors.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.PrepareRequest = func(r *http.Request) error {
+		return errors.New("no session")
+	}
+
+	t.Log("Expect BuildSingle to error with an unable to prepare http.Request error.")
+	path := "/hello"
+	expectedErrString := "Unable to prepare http.Request for path /hello: no session"
+	status, outputPath, err := static.BuildSingle(options, handler, path)
+	if err == nil || !strings.Contains(err.Error(), expectedErrString) {
+		t.Errorf("BuildSingle(%#v) => %v, %v, %v, expected a %s error", path, status, outputPath, err, expectedErrString)
+	}
+}
@@ -1,15 +1,31 @@
 package static
 
 import (
+	"bufio"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"time"
 )
 
+// sniffLen is the number of bytes of the body that are used to detect the Content-Type, the same as http.DetectContentType.
+const sniffLen = 512
+
 type responseWriter struct {
 	writer     io.Writer
 	header     http.Header
 	statusCode int
 	statusSet  bool
+	// The header as it was when the status was written, which later changes to the header do not affect, the same as net/http.
+	written http.Header
+	// Whether the Content-Type is still to be detected from the first bytes written to the body, which are held in sniffed until there are sniffLen of them, the handler flushes, or the response is finished, the same as net/http.
+	sniffing bool
+	sniffed  []byte
+	// Whether the request is a HEAD request, and so the body is discarded, the same as net/http.
+	head     bool
+	bodyLen  int64
+	warnings []string
 }
 
 func newResponseWriter(w io.Writer) responseWriter {
@@ -23,18 +39,198 @@ func (rc *responseWriter) Header() http.Header {
 	return rc.header
 }
 
+// WriteHeader writes the status code, and takes a snapshot of the header. Calls after the status code is written, including implicitly by Write, are superfluous and only recorded as warnings, as are informational 1xx responses, the same as net/http.
 func (rc *responseWriter) WriteHeader(statusCode int) {
+	if rc.statusSet {
+		rc.warn(fmt.Sprintf("superfluous WriteHeader call with status %d", statusCode))
+		return
+	}
+	if statusCode < 100 || statusCode > 999 {
+		rc.warn(fmt.Sprintf("invalid WriteHeader call with status %d", statusCode))
+		return
+	}
+	if statusCode >= 100 && statusCode <= 199 && statusCode != http.StatusSwitchingProtocols {
+		rc.warn(fmt.Sprintf("informational response with status %d is not written", statusCode))
+		return
+	}
 	rc.statusCode = statusCode
 	rc.statusSet = true
+	rc.written = rc.Header().Clone()
+	_, haveType := rc.written["Content-Type"]
+	rc.sniffing = !haveType && rc.written.Get("Transfer-Encoding") == "" && bodyAllowed(statusCode)
 }
 
 func (rc *responseWriter) StatusCode() int {
 	return rc.statusCode
 }
 
+// ResponseHeader returns the header as it was when the status code was written, including a detected Content-Type. Returns the current header if the status code has not been written.
+func (rc *responseWriter) ResponseHeader() http.Header {
+	if rc.written == nil {
+		return rc.Header()
+	}
+	return rc.written
+}
+
+// Warnings returns the ways the handler used the responseWriter that net/http would ignore.
+func (rc *responseWriter) Warnings() []string {
+	return rc.warnings
+}
+
+func (rc *responseWriter) warn(warning string) {
+	rc.warnings = append(rc.warnings, warning)
+}
+
 func (rc *responseWriter) Write(p []byte) (n int, err error) {
 	if !rc.statusSet {
-		rc.statusCode = http.StatusOK
+		rc.WriteHeader(http.StatusOK)
+	}
+	if !bodyAllowed(rc.statusCode) {
+		return 0, http.ErrBodyNotAllowed
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if rc.sniffing {
+		rc.sniffed = append(rc.sniffed, p...)
+		if len(rc.sniffed) < sniffLen {
+			return len(p), nil
+		}
+		err = rc.sniff()
+		if err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	return rc.writeBody(p)
+}
+
+// sniff detects the Content-Type from the bytes held for sniffing, if there are any, and writes them to the Writer. Bytes written after are not held.
+func (rc *responseWriter) sniff() error {
+	rc.sniffing = false
+	if len(rc.sniffed) == 0 {
+		return nil
+	}
+	rc.written.Set("Content-Type", http.DetectContentType(rc.sniffed))
+	_, err := rc.writeBody(rc.sniffed)
+	rc.sniffed = nil
+	return err
+}
+
+// writeBody writes the bytes to the Writer, or discards them for a HEAD request.
+func (rc *responseWriter) writeBody(p []byte) (n int, err error) {
+	if rc.head {
+		return len(p), nil
+	}
+	n, err = rc.writer.Write(p)
+	rc.bodyLen += int64(n)
+	return n, err
+}
+
+// BodyLen returns the number of bytes of the body written to the Writer.
+func (rc *responseWriter) BodyLen() int64 {
+	return rc.bodyLen
+}
+
+// finish completes the response after the handler has returned, writing the status code 200 OK if the handler wrote nothing, and detecting the Content-Type from a body shorter than sniffLen.
+func (rc *responseWriter) finish() error {
+	if !rc.statusSet {
+		rc.WriteHeader(http.StatusOK)
+	}
+	return rc.sniff()
+}
+
+// WriteString writes the string to the Writer, in the same way as Write.
+func (rc *responseWriter) WriteString(s string) (n int, err error) {
+	if !rc.statusSet || rc.sniffing || rc.head || !bodyAllowed(rc.statusCode) {
+		return rc.Write([]byte(s))
+	}
+	n, err = io.WriteString(rc.writer, s)
+	rc.bodyLen += int64(n)
+	return n, err
+}
+
+// ReadFrom copies from the io.Reader to the Writer, in the same way as Write, using the Writer's ReadFrom if it has one so copies from files are efficient.
+func (rc *responseWriter) ReadFrom(r io.Reader) (n int64, err error) {
+	if !rc.statusSet {
+		rc.WriteHeader(http.StatusOK)
+	}
+	if !bodyAllowed(rc.statusCode) {
+		return 0, http.ErrBodyNotAllowed
+	}
+	if rc.sniffing {
+		buf := make([]byte, sniffLen)
+		m, err := io.ReadFull(r, buf)
+		if m > 0 {
+			_, werr := rc.Write(buf[:m])
+			if werr != nil {
+				return 0, werr
+			}
+		}
+		n = int64(m)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	if rc.head {
+		m, err := io.Copy(io.Discard, r)
+		return n + m, err
+	}
+	m, err := io.Copy(rc.writer, r)
+	rc.bodyLen += m
+	return n + m, err
+}
+
+// Flush flushes the Writer if it can be flushed, so that handlers that stream their response build the same way they serve.
+func (rc *responseWriter) Flush() {
+	rc.FlushError()
+}
+
+// FlushError writes the status code 200 OK if it has not been written, detects the Content-Type from what has been written so far, and flushes the Writer if it can be flushed, returning any error. It is used by http.ResponseController.
+func (rc *responseWriter) FlushError() error {
+	if !rc.statusSet {
+		rc.WriteHeader(http.StatusOK)
+	}
+	err := rc.sniff()
+	if err != nil {
+		return err
+	}
+	switch w := rc.writer.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case http.Flusher:
+		w.Flush()
+	}
+	return nil
+}
+
+// Hijack returns an error because there is no connection to take over when building.
+func (rc *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return nil, nil, buildError{"Unable to hijack the connection of a response being built", http.ErrNotSupported}
+}
+
+// SetReadDeadline does nothing because the request being built has no body to read. It is used by http.ResponseController.
+func (rc *responseWriter) SetReadDeadline(deadline time.Time) error {
+	return nil
+}
+
+// SetWriteDeadline does nothing because the response being built is not written to a connection. It is used by http.ResponseController.
+func (rc *responseWriter) SetWriteDeadline(deadline time.Time) error {
+	return nil
+}
+
+// bodyAllowed reports whether a response with the status code is permitted to have a body, the same as net/http.
+func bodyAllowed(statusCode int) bool {
+	switch {
+	case statusCode >= 100 && statusCode <= 199:
+		return false
+	case statusCode == http.StatusNoContent:
+		return false
+	case statusCode == http.StatusNotModified:
+		return false
 	}
-	return rc.writer.Write(p)
+	return true
 }
@@ -2,9 +2,13 @@ package static
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"net/http"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestResponseWriterHeader(t *testing.T) {
@@ -56,15 +60,20 @@ func TestResponseWriterStatusCodeAfterWriteHeader(t *testing.T) {
 	}
 
 	t.Log("When WriteHeader has been called multiple times.")
-	t.Log("Expect StatusCode to be the last status code.")
+	t.Log("Expect StatusCode to be the first status code, and the superfluous call to be a warning.")
 	{
-		expectedStatusCode := 403
+		expectedStatusCode := 404
 		responseWriter.WriteHeader(403)
 		t.Logf("WriteHeader(403)")
 		statusCode := responseWriter.StatusCode()
 		t.Logf("StatusCode => %d", statusCode)
 		if statusCode != expectedStatusCode {
-			t.Logf("StatusCode => %d, want %d", statusCode, expectedStatusCode)
+			t.Errorf("StatusCode => %d, want %d", statusCode, expectedStatusCode)
+		}
+		expectedWarnings := []string{"superfluous WriteHeader call with status 403"}
+		warnings := responseWriter.Warnings()
+		if !reflect.DeepEqual(warnings, expectedWarnings) {
+			t.Errorf("Warnings => %#v, want %#v", warnings, expectedWarnings)
 		}
 	}
 }
@@ -108,8 +117,8 @@ func TestResponseWriterStatusCodeAfterWriteAndWriteHeader(t *testing.T) {
 	responseWriter := newResponseWriter(&writtenBuffer)
 
 	t.Log("When Write and WriteHeader have been called.")
-	t.Log("Expect StatusCode to be the WriteHeader input.")
-	expectedStatusCode := 404
+	t.Log("Expect StatusCode to be 200 OK because the status was written by Write.")
+	expectedStatusCode := 200
 	responseWriter.Write([]byte{})
 	t.Logf("Write([]byte{})")
 	responseWriter.WriteHeader(404)
@@ -124,6 +133,7 @@ func TestResponseWriterStatusCodeAfterWriteAndWriteHeader(t *testing.T) {
 func TestResponseWriterWrite(t *testing.T) {
 	writtenBuffer := bytes.Buffer{}
 	responseWriter := newResponseWriter(&writtenBuffer)
+	responseWriter.Header().Set("Content-Type", "application/octet-stream")
 
 	t.Log("When nothing has been written to the responseWriter.")
 	t.Log("Expect nothing will be written to the Writer.")
@@ -167,3 +177,167 @@ func TestResponseWriterWrite(t *testing.T) {
 		}
 	}
 }
+
+func TestResponseWriterWriteStringAndReadFrom(t *testing.T) {
+	writtenBuffer := bytes.Buffer{}
+	responseWriter := newResponseWriter(&writtenBuffer)
+
+	t.Log("When a string is written with io.WriteString and a reader is copied with io.Copy.")
+	io.WriteString(&responseWriter, "Hello ")
+	io.Copy(&responseWriter, strings.NewReader("World!"))
+	responseWriter.finish()
+
+	t.Log("Expect both to be written to the Writer and StatusCode to be 200 OK.")
+	written := writtenBuffer.String()
+	t.Logf("written => %q", written)
+	if written != "Hello World!" {
+		t.Errorf("written => %q, want %q", written, "Hello World!")
+	}
+	if statusCode := responseWriter.StatusCode(); statusCode != 200 {
+		t.Errorf("StatusCode => %d, want %d", statusCode, 200)
+	}
+}
+
+type flushBuffer struct {
+	bytes.Buffer
+	flushes int
+}
+
+func (b *flushBuffer) Flush() error {
+	b.flushes++
+	return nil
+}
+
+func TestResponseWriterResponseController(t *testing.T) {
+	writtenBuffer := flushBuffer{}
+	responseWriter := newResponseWriter(&writtenBuffer)
+	rc := http.NewResponseController(&responseWriter)
+
+	t.Log("When the responseWriter is used as an http.Flusher.")
+	t.Log("Expect the Writer to be flushed and StatusCode to be 200 OK.")
+	responseWriter.Flush()
+	if writtenBuffer.flushes != 1 {
+		t.Errorf("flushes => %d, want %d", writtenBuffer.flushes, 1)
+	}
+	if statusCode := responseWriter.StatusCode(); statusCode != 200 {
+		t.Errorf("StatusCode => %d, want %d", statusCode, 200)
+	}
+
+	t.Log("When the responseWriter is flushed through an http.ResponseController.")
+	t.Log("Expect the Writer to be flushed.")
+	err := rc.Flush()
+	if err != nil || writtenBuffer.flushes != 2 {
+		t.Errorf("Flush() => %v, flushes => %d, want nil, %d", err, writtenBuffer.flushes, 2)
+	}
+
+	t.Log("When deadlines are set through an http.ResponseController.")
+	t.Log("Expect no errors.")
+	err = rc.SetReadDeadline(time.Now())
+	if err != nil {
+		t.Errorf("SetReadDeadline() => %v, want nil", err)
+	}
+	err = rc.SetWriteDeadline(time.Now())
+	if err != nil {
+		t.Errorf("SetWriteDeadline() => %v, want nil", err)
+	}
+
+	t.Log("When the connection is hijacked through an http.ResponseController.")
+	t.Log("Expect an error that is http.ErrNotSupported.")
+	_, _, err = rc.Hijack()
+	t.Logf("Hijack() => %v", err)
+	if !errors.Is(err, http.ErrNotSupported) {
+		t.Errorf("Hijack() => %v, want %v", err, http.ErrNotSupported)
+	}
+}
+
+func TestResponseWriterStatusCodeAfterNothingWritten(t *testing.T) {
+	writtenBuffer := bytes.Buffer{}
+	responseWriter := newResponseWriter(&writtenBuffer)
+
+	t.Log("When the handler returns without writing anything.")
+	responseWriter.finish()
+
+	t.Log("Expect StatusCode to be 200 OK.")
+	if statusCode := responseWriter.StatusCode(); statusCode != 200 {
+		t.Errorf("StatusCode => %d, want %d", statusCode, 200)
+	}
+}
+
+func TestResponseWriterInformational(t *testing.T) {
+	writtenBuffer := bytes.Buffer{}
+	responseWriter := newResponseWriter(&writtenBuffer)
+
+	t.Log("When WriteHeader is called with 103 Early Hints and then Write is called.")
+	responseWriter.WriteHeader(103)
+	responseWriter.Write([]byte("Hello"))
+
+	t.Log("Expect StatusCode to be 200 OK, and the informational response to be a warning.")
+	if statusCode := responseWriter.StatusCode(); statusCode != 200 {
+		t.Errorf("StatusCode => %d, want %d", statusCode, 200)
+	}
+	expectedWarnings := []string{"informational response with status 103 is not written"}
+	if warnings := responseWriter.Warnings(); !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("Warnings => %#v, want %#v", warnings, expectedWarnings)
+	}
+}
+
+func TestResponseWriterBodyNotAllowed(t *testing.T) {
+	writtenBuffer := bytes.Buffer{}
+	responseWriter := newResponseWriter(&writtenBuffer)
+
+	t.Log("When WriteHeader is called with 204 No Content and then Write is called.")
+	responseWriter.WriteHeader(204)
+	n, err := responseWriter.Write([]byte("Hello"))
+
+	t.Log("Expect Write to return http.ErrBodyNotAllowed and nothing to be written.")
+	if n != 0 || err != http.ErrBodyNotAllowed {
+		t.Errorf("Write() => %d, %v, want %d, %v", n, err, 0, http.ErrBodyNotAllowed)
+	}
+	if writtenBuffer.Len() != 0 {
+		t.Errorf("written => %q, want nothing", writtenBuffer.String())
+	}
+}
+
+func TestResponseWriterResponseHeader(t *testing.T) {
+	tests := []struct {
+		description         string
+		contentType         string
+		body                string
+		expectedContentType string
+	}{
+		{"no Content-Type and an HTML body", "", "<!DOCTYPE html><p>Hello</p>", "text/html; charset=utf-8"},
+		{"no Content-Type and a text body", "", "Hello", "text/plain; charset=utf-8"},
+		{"a Content-Type", "application/json", "Hello", "application/json"},
+		{"no Content-Type and no body", "", "", ""},
+		{"no Content-Type and an HTML body written in parts", "", "<!DOC|TYPE html>|<p>Hello</p>", "text/html; charset=utf-8"},
+		{"no Content-Type and an HTML body after the first 512 bytes", "", strings.Repeat("a", 512) + "|<!DOCTYPE html>", "text/plain; charset=utf-8"},
+	}
+
+	for _, test := range tests {
+		t.Logf("When a response has %s.", test.description)
+		writtenBuffer := bytes.Buffer{}
+		responseWriter := newResponseWriter(&writtenBuffer)
+		if test.contentType != "" {
+			responseWriter.Header().Set("Content-Type", test.contentType)
+		}
+		for _, part := range strings.Split(test.body, "|") {
+			responseWriter.Write([]byte(part))
+		}
+		responseWriter.finish()
+
+		t.Log("And the header is changed after the body is written.")
+		responseWriter.Header().Set("X-Late", "true")
+
+		t.Logf("Expect the Content-Type of the ResponseHeader to be %q, and the late change to not be in the ResponseHeader.", test.expectedContentType)
+		header := responseWriter.ResponseHeader()
+		if contentType := header.Get("Content-Type"); contentType != test.expectedContentType {
+			t.Errorf("Content-Type => %q, want %q", contentType, test.expectedContentType)
+		}
+		if late := header.Get("X-Late"); late != "" {
+			t.Errorf("X-Late => %q, want empty", late)
+		}
+		if written, body := writtenBuffer.String(), strings.ReplaceAll(test.body, "|", ""); written != body {
+			t.Errorf("written => %q, want %q", written, body)
+		}
+	}
+}
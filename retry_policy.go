@@ -0,0 +1,79 @@
+package static
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy for configuring how BuildSingle retries a path when the handler fails transiently. Get the default policy with DefaultRetryPolicy.
+type RetryPolicy struct {
+	// The maximum number of attempts for each path, including the first. If the last attempt is still retryable, the path fails and any existing output file is kept. Values less than 1 are treated as 1.
+	MaxAttempts int
+	// The delay before the first retry. The delay doubles for each retry after that.
+	Backoff time.Duration
+	// The maximum delay between attempts. Zero means the delay is not capped.
+	MaxBackoff time.Duration
+	// The fraction of each delay, between 0 and 1, that is randomized to spread out retries of concurrent builds.
+	Jitter float64
+	// The HTTP status codes that are retried. e.g. 500, 502, 503, 504
+	StatusCodes []int
+	// A function that reports if an attempt is retried given its status code and the error that occurred, if any. When set it is used instead of StatusCodes. When nil, attempts that errored are not retried.
+	Retryable func(statusCode int, err error) bool
+}
+
+// DefaultRetryPolicy contains the default recommended RetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     100 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+	Jitter:      0.5,
+	StatusCodes: []int{500, 502, 503, 504},
+}
+
+// maxAttempts returns the number of attempts that are made for each path.
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// retryable reports if an attempt that returned the status code and error should be retried.
+func (p *RetryPolicy) retryable(statusCode int, err error) bool {
+	if p == nil {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(statusCode, err)
+	}
+	if err != nil {
+		return false
+	}
+	for _, c := range p.StatusCodes {
+		if c == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the time to wait after the attempt before the next attempt.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && (p.MaxBackoff == 0 || d < p.MaxBackoff); i++ {
+		if d > math.MaxInt64/2 {
+			d = math.MaxInt64
+			break
+		}
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		jitter := time.Duration(float64(d) * p.Jitter * rand.Float64())
+		d -= jitter
+	}
+	return d
+}
@@ -0,0 +1,120 @@
+package static_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"4d63.com/static"
+)
+
+func TestBuildRetry(t *testing.T) {
+	t.Log("When a Handler is defined to respond with 503 Service Unavailable twice and then with Hello <path>!")
+	attempts := 0
+	handler := http.NewServeMux()
+	handler.HandleFunc("/hello/", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts <= 2 {
+			w.WriteHeader(503)
+			fmt.Fprintf(w, "Unavailable %d", attempts)
+			return
+		}
+		fmt.Fprintf(w, "Hello %s!", filepath.Base(r.URL.Path))
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, and a RetryPolicy of 3 attempts.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	retry := static.DefaultRetryPolicy
+	retry.Backoff = time.Millisecond
+	options.Retry = &retry
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And the path to build is /hello/world.")
+	paths := []string{"/hello/world"}
+
+	t.Log("Expect Build to send a retry event for each failed attempt, a build event for the successful attempt, and write only the successful attempt.")
+	var events []static.Event
+	var eventsMutex sync.Mutex
+	static.Build(options, handler, paths, func(e static.Event) {
+		eventsMutex.Lock()
+		defer eventsMutex.Unlock()
+		t.Logf("Event received => %#v", e)
+		events = append(events, e)
+	})
+
+	expectedOutputFilePath := filepath.Join(options.OutputDir, "hello", "world")
+	expectedActions := []static.Action{static.RETRY, static.RETRY, static.BUILD}
+	expectedStatusCodes := []int{503, 503, 200}
+	if len(events) != len(expectedActions) {
+		t.Fatalf("Number of events received => %d, expected %d", len(events), len(expectedActions))
+	}
+	for i, e := range events {
+		if e.Action != expectedActions[i] || e.StatusCode != expectedStatusCodes[i] || e.OutputPath != expectedOutputFilePath {
+			t.Errorf("Event %d => %#v, expected Action %s, StatusCode %d, OutputPath %s", i, e, expectedActions[i], expectedStatusCodes[i], expectedOutputFilePath)
+		}
+	}
+	if events[2].Error != nil {
+		t.Errorf("Event 2 Error => %v, expected nil", events[2].Error)
+	}
+
+	expectedOutputFileContents := "Hello world!"
+	outputFileContents, err := ioutil.ReadFile(expectedOutputFilePath)
+	if err != nil {
+		t.Fatalf("Expected %s to exist with the output but got error when opening: %v", expectedOutputFilePath, err)
+	}
+	if string(outputFileContents) != expectedOutputFileContents {
+		t.Fatalf(`Contents of %s => %s, expected %s`, expectedOutputFilePath, outputFileContents, expectedOutputFileContents)
+	}
+}
+
+func TestBuildSingleRetryExhausted(t *testing.T) {
+	t.Log("When a Handler is defined to always respond with 503 Service Unavailable and the attempt number.")
+	attempts := 0
+	handler := http.NewServeMux()
+	handler.HandleFunc("/hello/", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(503)
+		fmt.Fprintf(w, "Unavailable %d", attempts)
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir with a good output file from a previous build, and a RetryPolicy of 2 attempts.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.Retry = &static.RetryPolicy{MaxAttempts: 2, StatusCodes: []int{503}}
+	t.Logf("OutputDir: %s", options.OutputDir)
+	expectedOutputFilePath := filepath.Join(options.OutputDir, "hello", "world")
+	expectedOutputFileContents := "Hello world!"
+	os.MkdirAll(filepath.Dir(expectedOutputFilePath), 0755)
+	ioutil.WriteFile(expectedOutputFilePath, []byte(expectedOutputFileContents), 0644)
+
+	t.Log("And the path to build is /hello/world.")
+	path := "/hello/world"
+
+	t.Log("Expect BuildSingle to attempt the path twice, return the status of the last attempt and an error, and keep the previous output file.")
+	expectedStatus := 503
+
+	status, outputPath, err := static.BuildSingle(options, handler, path)
+	t.Logf("BuildSingle(%#v) => %v, %v, %v", path, status, outputPath, err)
+	if status != expectedStatus || outputPath != "" || err == nil {
+		t.Errorf("BuildSingle(%#v) => %v, %v, %v, expected %v, \"\", an error", path, status, outputPath, err, expectedStatus)
+	}
+	if attempts != 2 {
+		t.Errorf("Attempts => %d, expected 2", attempts)
+	}
+
+	outputFileContents, err := ioutil.ReadFile(expectedOutputFilePath)
+	if err != nil {
+		t.Fatalf("Expected %s to exist with the output but got error when opening: %v", expectedOutputFilePath, err)
+	}
+	if string(outputFileContents) != expectedOutputFileContents {
+		t.Fatalf(`Contents of %s => %s, expected %s`, expectedOutputFilePath, outputFileContents, expectedOutputFileContents)
+	}
+}
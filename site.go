@@ -0,0 +1,56 @@
+package static
+
+import (
+	"iter"
+	"net/http"
+	"path/filepath"
+)
+
+// Site is a website served by the handler for a host, built with BuildSites.
+type Site struct {
+	// The host the site is served at, set as the Request Host and URL Host. e.g. example.com
+	Host string
+	// The scheme the site is served with, set as the Request URL Scheme. Requests are made as if over TLS when https. Empty means http.
+	Scheme string
+	// The paths to build.
+	Paths []string
+	// A source of further paths to build, listed while the build is running. May be nil.
+	Source iter.Seq[string]
+	// The directory in the OutputDir the site is written to. Empty means a directory named after the Host.
+	OutputDir string
+}
+
+// target returns the target for building the path of the base target for the site.
+func (s *Site) target(base buildTarget) buildTarget {
+	t := base
+	t.host = s.Host
+
+	request := RequestTemplate{}
+	if t.o.Request != nil {
+		request = *t.o.Request
+	}
+	request.Host = s.Host
+	request.Scheme = s.Scheme
+	t.o.Request = &request
+
+	scheme := s.Scheme
+	if scheme == "" {
+		scheme = "http"
+	}
+	prepare := t.o.PrepareRequest
+	t.o.PrepareRequest = func(r *http.Request) error {
+		r.URL.Scheme = scheme
+		r.URL.Host = s.Host
+		if prepare != nil {
+			return prepare(r)
+		}
+		return nil
+	}
+
+	outputDir := s.OutputDir
+	if outputDir == "" {
+		outputDir = s.Host
+	}
+	t.o.OutputDir = filepath.Join(t.o.OutputDir, outputDir)
+	return t
+}
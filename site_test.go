@@ -0,0 +1,62 @@
+package static_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"4d63.com/static"
+)
+
+func TestBuildSites(t *testing.T) {
+	t.Log("When a Handler is defined to respond with the host, URL and whether the request was over TLS.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s tls=%v", r.Host, r.URL, r.TLS != nil)
+	})
+
+	t.Log("And Options are defined with defaults and an OutputDir that does not exist.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	t.Logf("OutputDir: %s", options.OutputDir)
+
+	t.Log("And there are two sites, one served over https and one written to a custom directory.")
+	sites := []static.Site{
+		{Host: "example.com", Scheme: "https", Paths: []string{"/", "/about"}},
+		{Host: "blog.example.com", Paths: []string{"/posts/1"}, OutputDir: "blog"},
+	}
+
+	t.Log("Expect BuildSites to build each site's paths for its host into its own directory, with the host in each event.")
+	var mutex sync.Mutex
+	hosts := map[string]string{}
+	err := static.BuildSites(options, handler, sites, func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		mutex.Lock()
+		defer mutex.Unlock()
+		hosts[e.Path] = e.Host
+	})
+	if err != nil {
+		t.Errorf("BuildSites() => %v, expected nil", err)
+	}
+
+	expectedHosts := map[string]string{"/": "example.com", "/about": "example.com", "/posts/1": "blog.example.com"}
+	if fmt.Sprint(hosts) != fmt.Sprint(expectedHosts) {
+		t.Errorf("Hosts built => %v, expected %v", hosts, expectedHosts)
+	}
+
+	expectedContents := map[string]string{
+		filepath.Join("example.com", "index.html"): "example.com https://example.com/ tls=true",
+		filepath.Join("example.com", "about"):      "example.com https://example.com/about tls=true",
+		filepath.Join("blog", "posts", "1"):        "blog.example.com http://blog.example.com/posts/1 tls=false",
+	}
+	for file, expected := range expectedContents {
+		outputFilePath := filepath.Join(options.OutputDir, file)
+		contents, err := ioutil.ReadFile(outputFilePath)
+		if err != nil || string(contents) != expected {
+			t.Errorf("Contents of %s => %s, %v, expected %s", outputFilePath, contents, err, expected)
+		}
+	}
+}
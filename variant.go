@@ -0,0 +1,150 @@
+package static
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Variant is a representation of a path, built by requesting the path with an Accept header and writing the response to the path's output file with a suffix.
+type Variant struct {
+	// The Accept header the path is requested with. e.g. application/json
+	Accept string
+	// The suffix added to the output file of the path. Empty means the output file of the path is used, for the default representation. e.g. .json
+	Suffix string
+}
+
+// target returns the target for building the path of the base target in the variant, at the index of the variant in the path's Variants.
+func (v Variant) target(base buildTarget, index int) buildTarget {
+	t := base
+	t.variant = v
+	t.varied = true
+	t.variantIndex = index
+
+	request := RequestTemplate{}
+	if t.o.Request != nil {
+		request = *t.o.Request
+	}
+	request.Header = request.Header.Clone()
+	if request.Header == nil {
+		request.Header = http.Header{}
+	}
+	if v.Accept != "" {
+		request.Header.Set("Accept", v.Accept)
+	}
+	t.o.Request = &request
+	return t
+}
+
+// VariantManifest is the JSON manifest written to the VariantManifest path, listing the files built for each variant of each path, for static hosts and edge functions to route requests by their Accept header.
+type VariantManifest struct {
+	Paths []VariantManifestPath `json:"paths"`
+}
+
+// VariantManifestPath is a path in a VariantManifest, with the files built for its variants in the order the variants were defined.
+type VariantManifestPath struct {
+	Path     string                   `json:"path"`
+	Host     string                   `json:"host,omitempty"`
+	Locale   string                   `json:"locale,omitempty"`
+	Variants []VariantManifestVariant `json:"variants"`
+}
+
+// VariantManifestVariant is a variant of a path in a VariantManifest, and the file relative to the OutputDir it was written to, with forward slashes.
+type VariantManifestVariant struct {
+	Accept string `json:"accept"`
+	File   string `json:"file"`
+}
+
+// variantManifest collects the variants built successfully, for writing a VariantManifest.
+type variantManifest struct {
+	mutex   sync.Mutex
+	entries []variantManifestEntry
+}
+
+type variantManifestEntry struct {
+	path       string
+	host       string
+	locale     string
+	index      int
+	accept     string
+	outputPath string
+}
+
+func (m *variantManifest) record(t buildTarget, outputPath string, statusCode int, err error) {
+	if m == nil || err != nil || !built(statusCode) || !t.varied {
+		return
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.entries = append(m.entries, variantManifestEntry{
+		path:       t.path,
+		host:       t.host,
+		locale:     t.locale,
+		index:      t.variantIndex,
+		accept:     t.variant.Accept,
+		outputPath: outputPath,
+	})
+}
+
+// manifest returns the VariantManifest of the recorded variants, sorted by host, path and locale so the manifest is the same for every build of the same paths.
+func (m *variantManifest) manifest(outputDir string) VariantManifest {
+	entries := m.entries
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.host != b.host {
+			return a.host < b.host
+		}
+		if a.path != b.path {
+			return a.path < b.path
+		}
+		if a.locale != b.locale {
+			return a.locale < b.locale
+		}
+		return a.index < b.index
+	})
+
+	manifest := VariantManifest{Paths: []VariantManifestPath{}}
+	for _, e := range entries {
+		n := len(manifest.Paths)
+		if n == 0 || manifest.Paths[n-1].Path != e.path || manifest.Paths[n-1].Host != e.host || manifest.Paths[n-1].Locale != e.locale {
+			manifest.Paths = append(manifest.Paths, VariantManifestPath{Path: e.path, Host: e.host, Locale: e.locale})
+			n++
+		}
+		file, err := filepath.Rel(outputDir, e.outputPath)
+		if err != nil {
+			file = e.outputPath
+		}
+		p := &manifest.Paths[n-1]
+		p.Variants = append(p.Variants, VariantManifestVariant{Accept: e.accept, File: filepath.ToSlash(file)})
+	}
+	return manifest
+}
+
+// write the VariantManifest into the OutputDir, calling the EventHandler with the result.
+func (m *variantManifest) write(o Options, eh EventHandler) {
+	if m == nil {
+		return
+	}
+
+	outputPath := filepath.Join(o.OutputDir, filepath.FromSlash(o.VariantManifest))
+	f, err := createFile(o, outputPath, o.VariantManifest)
+	if err != nil {
+		eh(Event{Action: MANIFEST, Path: o.VariantManifest, Error: err})
+		return
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	err = encoder.Encode(m.manifest(o.OutputDir))
+	if err != nil {
+		message := fmt.Sprintf("Unable to write variant manifest %s", outputPath)
+		eh(Event{Action: MANIFEST, Path: o.VariantManifest, Error: buildError{message, err}})
+		return
+	}
+	err = setModTime(o, outputPath, o.VariantManifest, nil)
+	eh(Event{Action: MANIFEST, Path: o.VariantManifest, OutputPath: outputPath, Error: err})
+}
@@ -0,0 +1,89 @@
+package static_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"4d63.com/static"
+)
+
+func TestBuildVariants(t *testing.T) {
+	t.Log("When a Handler is defined to respond with JSON or HTML depending on the Accept header.")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == "application/json" {
+			fmt.Fprintf(w, `{"path":%q}`, r.URL.Path)
+			return
+		}
+		fmt.Fprintf(w, "<p>%s</p>", r.URL.Path)
+	})
+
+	t.Log("And Options are defined with defaults, an OutputDir that does not exist, a VariantManifest, and HTML and JSON Variants for paths under /api.")
+	options := static.DefaultOptions
+	tempDir, _ := ioutil.TempDir("", "")
+	options.OutputDir = filepath.Join(tempDir, "build")
+	options.VariantManifest = "/variants.json"
+	options.Variants = func(path string) []static.Variant {
+		if filepath.Dir(path) != "/api" {
+			return nil
+		}
+		return []static.Variant{
+			{Accept: "text/html"},
+			{Accept: "application/json", Suffix: ".json"},
+		}
+	}
+
+	t.Log("Expect Build to build paths under /api once for each variant, and other paths once.")
+	var mutex sync.Mutex
+	var events []static.Event
+	err := static.Build(options, handler, []string{"/api/b", "/api/a", "/about"}, func(e static.Event) {
+		t.Logf("Event received => %v", e)
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, e)
+	})
+	if err != nil {
+		t.Errorf("Build() => %v, expected nil", err)
+	}
+	if len(events) != 6 {
+		t.Errorf("Events received => %d, expected 6 for 5 builds and the manifest", len(events))
+	}
+
+	expectedContents := map[string]string{
+		"about":      "<p>/about</p>",
+		"api/a":      "<p>/api/a</p>",
+		"api/a.json": `{"path":"/api/a"}`,
+		"api/b":      "<p>/api/b</p>",
+		"api/b.json": `{"path":"/api/b"}`,
+	}
+	for file, expected := range expectedContents {
+		outputFilePath := filepath.Join(options.OutputDir, filepath.FromSlash(file))
+		contents, err := ioutil.ReadFile(outputFilePath)
+		if err != nil || string(contents) != expected {
+			t.Errorf("Contents of %s => %s, %v, expected %s", outputFilePath, contents, err, expected)
+		}
+	}
+
+	t.Log("Expect the VariantManifest to list the variants of each path in the order they were defined, sorted by path.")
+	contents, err := ioutil.ReadFile(filepath.Join(options.OutputDir, "variants.json"))
+	if err != nil {
+		t.Fatalf("Error reading manifest => %v, expected to exist", err)
+	}
+	var manifest static.VariantManifest
+	err = json.Unmarshal(contents, &manifest)
+	if err != nil {
+		t.Fatalf("Error decoding manifest => %v", err)
+	}
+	expectedManifest := static.VariantManifest{Paths: []static.VariantManifestPath{
+		{Path: "/api/a", Variants: []static.VariantManifestVariant{{Accept: "text/html", File: "api/a"}, {Accept: "application/json", File: "api/a.json"}}},
+		{Path: "/api/b", Variants: []static.VariantManifestVariant{{Accept: "text/html", File: "api/b"}, {Accept: "application/json", File: "api/b.json"}}},
+	}}
+	if !reflect.DeepEqual(manifest, expectedManifest) {
+		t.Errorf("Manifest => %+v, expected %+v", manifest, expectedManifest)
+	}
+}